@@ -1,11 +1,21 @@
 package gobuild
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // BinarySizer formats binary sizes in human-readable format
 type BinarySizer struct {
-	getBinary func() []byte
-	log       func(...any)
+	getBinary     func() []byte
+	getCompressed func() []CompressedSize
+	log           func(...any)
+}
+
+// CompressedSize is the size of one precompressed encoding, eg: {Label: "gz", Size: 655360}
+type CompressedSize struct {
+	Label string
+	Size  int64
 }
 
 // NewBinarySizer creates a new BinarySizer instance
@@ -23,8 +33,13 @@ func (b *BinarySizer) SetLog(f func(...any)) {
 	}
 }
 
+// SetCompressedSizes adds the precompressed sizes to BinarySize, eg: "2.1 MB (gz 640.0 KB)"
+func (b *BinarySizer) SetCompressedSizes(f func() []CompressedSize) {
+	b.getCompressed = f
+}
+
 // BinarySize returns the binary size in human-readable format
-// Returns format: "10.4 KB", "2.3 MB", "1.5 GB"
+// Returns format: "10.4 KB", "2.3 MB", "1.5 GB", "2.1 MB (gz 640.0 KB)"
 // Returns "0.0 KB" if binary is unavailable or empty
 func (b *BinarySizer) BinarySize() string {
 	if b.getBinary == nil {
@@ -36,7 +51,24 @@ func (b *BinarySizer) BinarySize() string {
 		return "0.0 KB"
 	}
 
-	size := float64(len(bytes))
+	size := formatSize(int64(len(bytes)))
+	if b.getCompressed == nil {
+		return size
+	}
+
+	var compressed []string
+	for _, c := range b.getCompressed() {
+		compressed = append(compressed, c.Label+" "+formatSize(c.Size))
+	}
+	if len(compressed) > 0 {
+		size += " (" + strings.Join(compressed, ", ") + ")"
+	}
+	return size
+}
+
+// formatSize formats n bytes as "10.4 KB", "2.3 MB" or "1.5 GB"
+func formatSize(n int64) string {
+	size := float64(n)
 
 	// Thresholds
 	const (
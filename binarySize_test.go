@@ -329,3 +329,20 @@ func main() {
 		t.Errorf("Expected size format with KB/MB/GB, got '%s'", sizeStr)
 	}
 }
+
+// TestBinarySizer_CompressedSizes tests the precompressed sizes suffix
+func TestBinarySizer_CompressedSizes(t *testing.T) {
+	sizer := NewBinarySizer(func() []byte { return make([]byte, 2200*1024) })
+	sizer.SetCompressedSizes(func() []CompressedSize {
+		return []CompressedSize{{Label: "gz", Size: 640 * 1024}, {Label: "br", Size: 512 * 1024}}
+	})
+
+	if got, want := sizer.BinarySize(), "2.1 MB (gz 640.0 KB, br 512.0 KB)"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	sizer.SetCompressedSizes(func() []CompressedSize { return nil })
+	if got := sizer.BinarySize(); got != "2.1 MB" {
+		t.Errorf("Expected no suffix without compressed sizes, got %q", got)
+	}
+}
@@ -0,0 +1,362 @@
+package gobuild
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cacheFormat is part of every key, bumped when manifests record new inputs
+// eg: 2 added the embed globs, older entries miss instead of ignoring new assets
+const cacheFormat = 2
+
+// defaultBuildCacheMaxBytes bounds Config.BuildCacheDir when BuildCacheMaxBytes is not set
+const defaultBuildCacheMaxBytes = 512 << 20
+
+// cacheManifest records the inputs an artifact was built from
+type cacheManifest struct {
+	Files    map[string]string // absolute path: sha256 of the content
+	Dirs     map[string]string // package directory: sha256 of its file names, catches added files
+	Embeds   map[string]string // embed glob: sha256 of its matches, catches added assets
+	Artifact string            // sha256 of the artifact, also its object file name
+}
+
+// cacheEntry is the cache slot of one build configuration
+// The key covers toolchain, arguments and environment, the manifest covers the sources
+type cacheEntry struct {
+	dir      string
+	key      string
+	maxBytes int64
+	manifest *cacheManifest // inputs snapshot taken before the build
+}
+
+// cacheEntry returns the cache slot for a build run with args, nil when the cache is disabled
+func (h *GoBuild) cacheEntry(args []string) *cacheEntry {
+	dir := h.config.BuildCacheDir
+	if dir == "" {
+		return nil
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(h.config.AppRootDir, dir)
+	}
+
+	maxBytes := h.config.BuildCacheMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultBuildCacheMaxBytes
+	}
+
+	hash := sha256.New()
+	fmt.Fprintln(hash, cacheFormat, h.config.AppRootDir, toolchainID(h.config.Command))
+	for _, arg := range withoutOutputFlag(args) {
+		fmt.Fprintln(hash, arg)
+	}
+	for _, env := range os.Environ() {
+		if strings.HasPrefix(env, "GO") || strings.HasPrefix(env, "CGO_") {
+			fmt.Fprintln(hash, env)
+		}
+	}
+	for _, env := range h.config.Env {
+		fmt.Fprintln(hash, env)
+	}
+
+	return &cacheEntry{
+		dir:      dir,
+		key:      hex.EncodeToString(hash.Sum(nil)),
+		maxBytes: maxBytes,
+	}
+}
+
+// logCacheError reports a failed snapshot or store, the build itself goes on uncached
+func (h *GoBuild) logCacheError(err error) {
+	if h.config.Logger != nil {
+		h.config.Logger("Build cache skipped:", err)
+	}
+}
+
+// toolchainID identifies the toolchain binary without running it, eg: after an upgrade
+func toolchainID(command string) string {
+	binary, err := exec.LookPath(command)
+	if err != nil {
+		return command
+	}
+	info, err := os.Stat(binary)
+	if err != nil {
+		return binary
+	}
+	return fmt.Sprintf("%s %d %d", binary, info.Size(), info.ModTime().UnixNano())
+}
+
+// withoutOutputFlag drops "-o <path>", the temp file name changes on every build
+func withoutOutputFlag(args []string) []string {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] == "-o" {
+			i++
+			continue
+		}
+		out = append(out, args[i])
+	}
+	return out
+}
+
+// lookup returns the cached artifact when every recorded input is unchanged
+// No process is spawned on this path
+func (c *cacheEntry) lookup() ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	raw, err := os.ReadFile(c.manifestPath())
+	if err != nil {
+		return nil, false
+	}
+
+	var m cacheManifest
+	if err := json.Unmarshal(raw, &m); err != nil || !m.current() {
+		return nil, false
+	}
+
+	object := c.objectPath(m.Artifact)
+	data, err := os.ReadFile(object)
+	if err != nil {
+		return nil, false
+	}
+
+	// mark as recently used for eviction
+	now := time.Now()
+	os.Chtimes(object, now, now)
+	return data, true
+}
+
+// snapshot records the build inputs, it runs before the build so edits made
+// while compiling invalidate the entry
+func (c *cacheEntry) snapshot(ctx context.Context, h *GoBuild) error {
+	if c == nil {
+		return nil
+	}
+
+	pkgs, err := h.listPackages(ctx)
+	if err != nil {
+		return err
+	}
+
+	m := &cacheManifest{Files: map[string]string{}, Dirs: map[string]string{}, Embeds: map[string]string{}}
+	for _, p := range pkgs {
+		if !p.local() {
+			continue
+		}
+		for _, file := range p.sourceFiles() {
+			m.Files[file] = hashFile(file)
+		}
+		m.Dirs[p.Dir] = hashDirNames(p.Dir)
+		for _, glob := range p.embedGlobs() {
+			m.Embeds[glob] = hashGlob(glob)
+		}
+	}
+	for _, file := range moduleFiles(pkgs) {
+		m.Files[file] = hashFile(file)
+	}
+	for _, file := range h.workFiles() {
+		m.Files[file] = hashFile(file)
+	}
+
+	c.manifest = m
+	return nil
+}
+
+// workFiles returns the go.work and go.work.sum that can apply to the build, missing ones included
+// so creating a workspace later invalidates the entry, eg: GOWORK=off gives none
+func (h *GoBuild) workFiles() []string {
+	var dirs []string
+	switch gowork := envValue(h.buildEnv(), "GOWORK"); gowork {
+	case "off":
+		return nil
+	case "":
+		// go looks for go.work from the working directory up
+		dir, err := filepath.Abs(h.config.AppRootDir)
+		if err != nil {
+			return nil
+		}
+		for {
+			dirs = append(dirs, dir)
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	default:
+		if !filepath.IsAbs(gowork) {
+			gowork = filepath.Join(h.config.AppRootDir, gowork)
+		}
+		return []string{gowork, gowork + ".sum"}
+	}
+
+	var files []string
+	for _, dir := range dirs {
+		files = append(files, filepath.Join(dir, "go.work"), filepath.Join(dir, "go.work.sum"))
+	}
+	return files
+}
+
+// store saves data as the artifact of the snapshot taken before the build
+func (c *cacheEntry) store(data []byte) error {
+	if c == nil || c.manifest == nil {
+		return nil
+	}
+
+	sum := sha256.Sum256(data)
+	c.manifest.Artifact = hex.EncodeToString(sum[:])
+
+	raw, err := json.Marshal(c.manifest)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range []string{"objects", "manifests"} {
+		if err := os.MkdirAll(filepath.Join(c.dir, sub), 0755); err != nil {
+			return err
+		}
+	}
+
+	if err := writeFileAtomic(c.objectPath(c.manifest.Artifact), data); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(c.manifestPath(), raw); err != nil {
+		return err
+	}
+
+	return c.evict()
+}
+
+// evict removes the least recently used objects until the cache fits maxBytes
+// Manifests pointing to removed objects simply miss
+func (c *cacheEntry) evict() error {
+	entries, err := os.ReadDir(filepath.Join(c.dir, "objects"))
+	if err != nil {
+		return err
+	}
+
+	type object struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var objects []object
+	var total int64
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || info.IsDir() {
+			continue
+		}
+		objects = append(objects, object{filepath.Join(c.dir, "objects", e.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].modTime.Before(objects[j].modTime) })
+
+	for _, o := range objects {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(o.path); err == nil {
+			total -= o.size
+		}
+	}
+	return nil
+}
+
+func (c *cacheEntry) manifestPath() string {
+	return filepath.Join(c.dir, "manifests", c.key+".json")
+}
+
+func (c *cacheEntry) objectPath(artifact string) string {
+	return filepath.Join(c.dir, "objects", artifact)
+}
+
+// current reports whether every recorded input still has the same content
+func (m *cacheManifest) current() bool {
+	if m.Artifact == "" {
+		return false
+	}
+	for file, sum := range m.Files {
+		if hashFile(file) != sum {
+			return false
+		}
+	}
+	for dir, sum := range m.Dirs {
+		if hashDirNames(dir) != sum {
+			return false
+		}
+	}
+	for glob, sum := range m.Embeds {
+		if hashGlob(glob) != sum {
+			return false
+		}
+	}
+	return true
+}
+
+// hashFile returns the sha256 of the file content, "" when unreadable
+func hashFile(name string) string {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// sourceExtensions are the file kinds go build may pick up from a package directory
+var sourceExtensions = map[string]bool{
+	".go": true, ".c": true, ".cc": true, ".cpp": true, ".cxx": true, ".h": true, ".hh": true,
+	".hpp": true, ".hxx": true, ".s": true, ".S": true, ".sx": true, ".m": true, ".syso": true,
+}
+
+// hashDirNames returns the sha256 of the source file names in dir, "" when unreadable
+// Other names are ignored, eg: the build output written next to main.go
+func hashDirNames(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.New()
+	for _, e := range entries {
+		if !e.IsDir() && sourceExtensions[filepath.Ext(e.Name())] {
+			fmt.Fprintln(hash, e.Name())
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// hashGlob returns the sha256 of the paths matching glob, files and directories alike
+func hashGlob(glob string) string {
+	matches, _ := filepath.Glob(glob)
+	hash := sha256.New()
+	for _, match := range matches {
+		fmt.Fprintln(hash, match)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// writeFileAtomic writes data next to name and renames it into place
+func writeFileAtomic(name string, data []byte) error {
+	tmp := fmt.Sprintf("%s.tmp%d", name, time.Now().UnixNano())
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
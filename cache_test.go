@@ -0,0 +1,200 @@
+package gobuild
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildCacheSkipsToolchainOnHit(t *testing.T) {
+	tempDir := t.TempDir()
+	writeMainGo(t, tempDir, "Hello from cache test!")
+
+	runs := filepath.Join(tempDir, "runs.log")
+	tool := writeFakeToolchain(t, tempDir, `echo run >> "`+runs+`"
+`+fakeBuildScript)
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "cached",
+		OutFolderRelativePath:     tempDir,
+		BuildCacheDir:             filepath.Join(tempDir, "cache"),
+		Timeout:                   30 * time.Second,
+	})
+
+	countRuns := func() int {
+		data, _ := os.ReadFile(runs)
+		return strings.Count(string(data), "run")
+	}
+
+	first, err := gb.CompileProgramResult()
+	if err != nil {
+		t.Fatalf("First build failed: %v", err)
+	}
+	if first.CacheHit {
+		t.Error("First build cannot be a cache hit")
+	}
+
+	os.Remove(gb.FinalOutputPath())
+
+	second, err := gb.CompileProgramResult()
+	if err != nil {
+		t.Fatalf("Second build failed: %v", err)
+	}
+	if !second.CacheHit || countRuns() != 1 {
+		t.Errorf("Expected a cache hit without running the toolchain, hit=%v runs=%d", second.CacheHit, countRuns())
+	}
+	if second.SHA256 != first.SHA256 {
+		t.Errorf("Expected the cached artifact, got sha %s want %s", second.SHA256, first.SHA256)
+	}
+	if _, err := os.Stat(gb.FinalOutputPath()); err != nil {
+		t.Errorf("Expected the cached artifact to be promoted: %v", err)
+	}
+
+	memory, err := gb.CompileToMemoryResult()
+	if err != nil {
+		t.Fatalf("Memory build failed: %v", err)
+	}
+	if !memory.CacheHit || string(memory.Bytes) != "fake binary\n" {
+		t.Errorf("Expected in-memory cache hit, hit=%v bytes=%q", memory.CacheHit, memory.Bytes)
+	}
+
+	// Editing a source file invalidates the entry
+	writeMainGo(t, tempDir, "Edited!")
+	third, err := gb.CompileProgramResult()
+	if err != nil {
+		t.Fatalf("Third build failed: %v", err)
+	}
+	if third.CacheHit || countRuns() != 2 {
+		t.Errorf("Expected a miss after editing main.go, hit=%v runs=%d", third.CacheHit, countRuns())
+	}
+}
+
+func TestBuildCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	manifest := func() *cacheManifest {
+		return &cacheManifest{Files: map[string]string{}, Dirs: map[string]string{}}
+	}
+
+	old := &cacheEntry{dir: dir, key: "old", maxBytes: 15, manifest: manifest()}
+	if err := old.store([]byte("0123456789")); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+
+	// make sure the second object is more recent
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(old.objectPath(old.manifest.Artifact), past, past)
+
+	recent := &cacheEntry{dir: dir, key: "recent", maxBytes: 15, manifest: manifest()}
+	if err := recent.store([]byte("abcdefghij")); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+
+	if _, ok := old.lookup(); ok {
+		t.Error("Expected the least recently used object to be evicted")
+	}
+	if data, ok := recent.lookup(); !ok || string(data) != "abcdefghij" {
+		t.Errorf("Expected the recent object to be kept, got %q %v", data, ok)
+	}
+}
+
+func TestBuildCacheWorkspaceAndSnapshotErrors(t *testing.T) {
+	tempDir := t.TempDir()
+	writeMainGo(t, tempDir, "Hello from workspace test!")
+	if err := os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module example.com/app\n\ngo 1.22.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var logged []string
+	config := &Config{
+		AppRootDir:                tempDir,
+		Command:                   writeFakeToolchain(t, tempDir, fakeBuildScript),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "cached",
+		OutFolderRelativePath:     tempDir,
+		BuildCacheDir:             filepath.Join(tempDir, "cache"),
+		Timeout:                   30 * time.Second,
+		Logger:                    func(message ...any) { logged = append(logged, fmt.Sprint(message...)) },
+	}
+	gb := New(config)
+
+	gb.CompileProgramResult()
+	if result, err := gb.CompileProgramResult(); err != nil || !result.CacheHit {
+		t.Fatalf("Expected a hit before go.work exists, got %+v %v", result, err)
+	}
+
+	// A new workspace can change the build without touching the module
+	if err := os.WriteFile(filepath.Join(tempDir, "go.work"), []byte("go 1.22.0\n\nuse .\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := gb.CompileProgramResult(); err != nil || result.CacheHit {
+		t.Errorf("Expected a miss after creating go.work, got %+v %v", result, err)
+	}
+
+	// A failing listing disables the cache for the build and says why
+	config.Env = []string{"GOFLAGS=-mod=bogus"}
+	config.BuildCacheDir = filepath.Join(tempDir, "other-cache")
+	if _, err := New(config).CompileProgramResult(); err != nil {
+		t.Fatalf("Expected the build to go on uncached, got %v", err)
+	}
+	if !strings.Contains(strings.Join(logged, "\n"), "Build cache skipped:") {
+		t.Errorf("Expected the snapshot error to be logged, got %q", logged)
+	}
+}
+
+func TestBuildCacheEmbeddedDirectories(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"go.mod":          "module example.com/app\n\ngo 1.22.0\n",
+		"main.go":         "package main\n\nimport \"embed\"\n\n//go:embed static\nvar static embed.FS\n\n//go:embed *.txt\nvar notes embed.FS\n\nfunc main() {}\n",
+		"static/a.txt":    "a",
+		"static/img/x.js": "x",
+		"notes.txt":       "notes",
+	})
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   writeFakeToolchain(t, tempDir, fakeBuildScript),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "embedded",
+		OutFolderRelativePath:     filepath.Join(tempDir, "public"),
+		BuildCacheDir:             filepath.Join(tempDir, "cache"),
+		Timeout:                   30 * time.Second,
+	})
+	os.MkdirAll(filepath.Join(tempDir, "public"), 0755)
+
+	hit := func() bool {
+		t.Helper()
+		result, err := gb.CompileProgramResult()
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		return result.CacheHit
+	}
+
+	hit()
+	if !hit() {
+		t.Fatal("Expected a hit with unchanged inputs")
+	}
+
+	for _, added := range []string{"static/b.txt", "static/img/y.js", "static/fonts/f.woff", "more.txt"} {
+		writeFiles(t, tempDir, map[string]string{added: "new"})
+		if hit() {
+			t.Errorf("Expected a miss after adding embedded %s", added)
+		}
+		if !hit() {
+			t.Errorf("Expected a hit after rebuilding with %s", added)
+		}
+	}
+
+	// files outside the patterns are not embedded
+	writeFiles(t, tempDir, map[string]string{"README.md": "docs"})
+	if !hit() {
+		t.Error("Expected a file outside the embed patterns to keep the hit")
+	}
+}
@@ -2,43 +2,70 @@ package gobuild
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path"
+	"slices"
 	"strings"
+	"time"
 )
 
 // compileSync performs the actual compilation synchronously with context timeout
-func (h *GoBuild) compileSync(ctx context.Context, comp *compilation) error {
-	var e = errors.New("compileSync")
-
+func (h *GoBuild) compileSync(ctx context.Context, comp *compilation) (*BuildResult, error) {
+	if err := h.checkArguments(); err != nil {
+		return nil, err
+	}
+	if err := h.ensureProfiler(ctx); err != nil {
+		return nil, err
+	}
 	buildArgs := h.buildArguments(comp.tempFile)
+	// event flags stay out of buildArgs, the build cache key ignores them
+	events, eventFlags := h.startEvents(ctx, comp)
+	cmdArgs := slices.Insert(slices.Clone(buildArgs), 1, eventFlags...)
 
-	comp.cmd = exec.CommandContext(ctx, h.config.Command, buildArgs...)
+	comp.cmd = exec.CommandContext(ctx, h.config.Command, cmdArgs...)
 
 	// Set working directory to project root for relative paths resolution
 	comp.cmd.Dir = h.config.AppRootDir
-
-	// Set environment variables if provided
-	if len(h.config.Env) > 0 {
-		comp.cmd.Env = append(os.Environ(), h.config.Env...)
+	h.configureProcess(comp.cmd)
+
+	// Inherit the process environment, isolated dirs and Config.Env overrides
+	comp.cmd.Env = h.buildEnv()
+
+	// Stream stdout and stderr together while keeping the whole output for errors
+	out := h.newOutputWriter()
+	comp.cmd.Stdout = out
+	comp.cmd.Stderr = out
+	events.attach(comp.cmd, out)
+
+	result := newBuildResult(comp)
+	tempPath := path.Join(h.config.OutFolderRelativePath, comp.tempFile)
+
+	// Unchanged inputs: restore the artifact without running the toolchain
+	cache := h.cacheEntry(buildArgs)
+	if data, ok := cache.lookup(); ok {
+		events.finish()
+		if err := os.WriteFile(tempPath, data, 0755); err != nil {
+			h.cleanupTempFile(comp.tempFile)
+			return nil, &BuildError{Kind: ErrOutputPromotion, Err: err}
+		}
+		result.CacheHit = true
+		return h.promote(ctx, comp, result, data)
+	}
+	if err := cache.snapshot(ctx, h); err != nil {
+		h.logCacheError(err)
 	}
+	profile := h.startProfile(comp.cmd)
 
-	// Use CombinedOutput for simpler and more reliable error capture
-	output, err := comp.cmd.CombinedOutput()
+	err := comp.cmd.Run()
+	reapProcessGroup(comp.cmd)
+	events.finish()
+	out.Flush()
+	output := out.Bytes()
+	result.Profile = profile.finish()
 
 	if err != nil {
-		// Emit a single log entry containing the error and the raw build output (no processing)
-		errMsg := fmt.Sprintf("%v build failed: %v", e, err)
-		if ctx.Err() == context.DeadlineExceeded {
-			errMsg = fmt.Sprintf("%v build timeout after %v: %v", e, h.config.Timeout, err)
-		}
-
-		if len(output) > 0 {
-			errMsg += " " + string(output)
-		}
 		// Clean up temporary file if compilation failed
 		h.cleanupTempFile(comp.tempFile)
 
@@ -49,17 +76,111 @@ func (h *GoBuild) compileSync(ctx context.Context, comp *compilation) error {
 		// failures where compilation appeared successful but the final binary
 		// was missing. Returning the error here ensures callers handle timeouts
 		// and cancellations as failures and the test paths behave correctly.
-		return errors.New(errMsg)
+		return nil, h.newBuildError(ctx, err, output)
+	}
+
+	result.setOutput(h, output)
+
+	data, err := os.ReadFile(tempPath)
+	if err != nil {
+		h.cleanupTempFile(comp.tempFile)
+		return nil, &BuildError{Kind: ErrOutputPromotion, Err: err, Output: result.Output}
 	}
 
-	// fmt.Fprintf(h.config.Logger, "Compilation successful, renaming %s\n", comp.tempFile)
+	result, err = h.promote(ctx, comp, result, data)
+	if err != nil {
+		return nil, err
+	}
 
-	return h.renameOutputFile(comp.tempFile)
+	// only promoted artifacts are cached
+	if err := cache.store(data); err != nil {
+		h.logCacheError(err)
+	}
+	return result, nil
+}
+
+// promote post-processes the artifact of comp and renames its temp file to the final output
+// data is the content of the temp file
+func (h *GoBuild) promote(ctx context.Context, comp *compilation, result *BuildResult, data []byte) (*BuildResult, error) {
+	if len(h.config.PostProcessors) > 0 {
+		processed, err := h.postProcess(ctx, result, data)
+		if err != nil {
+			h.cleanupTempFile(comp.tempFile)
+			return nil, err
+		}
+		if err := os.WriteFile(path.Join(h.config.OutFolderRelativePath, comp.tempFile), processed, 0755); err != nil {
+			h.cleanupTempFile(comp.tempFile)
+			return nil, &BuildError{Kind: ErrOutputPromotion, Err: err, Output: result.Output}
+		}
+		data = processed
+	}
+	if err := h.validateArtifact(data); err != nil {
+		h.cleanupTempFile(comp.tempFile)
+		return nil, err
+	}
+	result.setArtifact(data)
+
+	compressed, err := h.compress(data)
+	if err != nil {
+		h.cleanupTempFile(comp.tempFile)
+		return nil, err
+	}
+	// siblings first, the output is never served next to stale ones
+	if err := h.writeCompressed(compressed); err != nil {
+		h.cleanupTempFile(comp.tempFile)
+		return nil, err
+	}
+	result.Compressed = compressed
+
+	// Same bytes as the current output: keep its mtime so reloaders do not fire
+	if h.config.SkipIdenticalOutput && hashFile(h.FinalOutputPath()) == result.SHA256 {
+		h.cleanupTempFile(comp.tempFile)
+		result.Unchanged = true
+	} else if err := h.renameOutputFile(comp.tempFile); err != nil {
+		h.cleanupTempFile(comp.tempFile)
+		return nil, err
+	}
+
+	h.mu.Lock()
+	h.memoryBytes = nil
+	h.memoryEncoded = nil
+	h.mu.Unlock()
+
+	// the build succeeded, a missing support file is only reported
+	if err := h.provisionWasmExec(); err != nil && h.config.Logger != nil {
+		h.config.Logger("wasm_exec.js not provisioned:", err)
+	}
+
+	result.OutputPath = h.FinalOutputPath()
+	result.Duration = time.Since(result.StartTime)
+	return result, nil
+}
+
+// newBuildError wraps a failed toolchain run with its raw output and parsed diagnostics
+func (h *GoBuild) newBuildError(ctx context.Context, err error, output []byte) *BuildError {
+	kind := errorKind(ctx, err)
+
+	// Keep the cancellation cause, eg: the caller's context.Canceled or its deadline
+	cause := context.Cause(ctx)
+	switch {
+	case cause == ErrTimeout:
+		err = fmt.Errorf("%w (limit %v)", err, h.config.Timeout)
+	case cause != nil && cause != ErrCanceled:
+		err = fmt.Errorf("%w (%w)", err, cause)
+	}
+
+	return &BuildError{
+		Kind:        kind,
+		Err:         err,
+		Output:      string(output),
+		Diagnostics: h.parseDiagnostics(string(output)),
+	}
 }
 
 // buildArguments constructs the command line arguments for go build
 func (h *GoBuild) buildArguments(tempFileName string) []string {
-	buildArgs := []string{"build"}
+	buildArgs := append([]string{"build"}, h.toolexecArgs()...)
+	buildArgs = append(buildArgs, h.config.TinyGo.args()...)
 	ldFlags := []string{}
 
 	if h.config.CompilingArguments != nil {
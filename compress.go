@@ -0,0 +1,137 @@
+package gobuild
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Compressor encodes the artifact into a precompressed sibling, eg: main.wasm.gz
+// zstd or brotli encoders plug in through this interface
+type Compressor interface {
+	Extension() string // appended to the output name, eg: .gz
+	Compress(data []byte) ([]byte, error)
+}
+
+// GzipCompressor returns a gzip Compressor writing .gz siblings
+// eg: GzipCompressor(gzip.BestCompression)
+func GzipCompressor(level int) Compressor {
+	return gzipCompressor{level: level}
+}
+
+type gzipCompressor struct {
+	level int
+}
+
+func (g gzipCompressor) Extension() string { return ".gz" }
+
+func (g gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, g.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CompressedArtifact is one Config.Compressors encoding of the artifact
+type CompressedArtifact struct {
+	Extension string // eg: .gz
+	Path      string // sibling of FinalOutputPath, disk builds only, eg: web/public/main.wasm.gz
+	Size      int64
+	Bytes     []byte // in-memory builds only
+}
+
+// compress encodes data with every Config.Compressors entry
+func (h *GoBuild) compress(data []byte) ([]CompressedArtifact, error) {
+	var artifacts []CompressedArtifact
+	for _, c := range h.config.Compressors {
+		out, err := c.Compress(data)
+		if err != nil {
+			return nil, &BuildError{Kind: ErrPostProcess, Err: fmt.Errorf("%s compressor: %w", c.Extension(), err)}
+		}
+		artifacts = append(artifacts, CompressedArtifact{Extension: c.Extension(), Size: int64(len(out)), Bytes: out})
+	}
+	return artifacts, nil
+}
+
+// writeCompressed writes each artifact next to FinalOutputPath and drops its Bytes
+// It runs before the output is renamed, changed siblings are staged first so a failed
+// write leaves the previous set in place, siblings with the same content are left untouched
+func (h *GoBuild) writeCompressed(artifacts []CompressedArtifact) error {
+	type stagedFile struct{ tmp, final string }
+	var staged []stagedFile
+	fail := func(err error) error {
+		for _, s := range staged {
+			os.Remove(s.tmp)
+		}
+		return &BuildError{Kind: ErrOutputPromotion, Err: err}
+	}
+
+	for i := range artifacts {
+		a := &artifacts[i]
+		a.Path = h.FinalOutputPath() + a.Extension
+
+		if current, err := os.ReadFile(a.Path); err == nil && bytes.Equal(current, a.Bytes) {
+			continue
+		}
+		tmp := fmt.Sprintf("%s.tmp%d", a.Path, time.Now().UnixNano())
+		staged = append(staged, stagedFile{tmp, a.Path})
+		if err := os.WriteFile(tmp, a.Bytes, 0644); err != nil {
+			return fail(err)
+		}
+	}
+
+	for len(staged) > 0 {
+		if err := os.Rename(staged[0].tmp, staged[0].final); err != nil {
+			return fail(err)
+		}
+		staged = staged[1:]
+	}
+
+	for i := range artifacts {
+		artifacts[i].Bytes = nil
+	}
+	return nil
+}
+
+// compressedSizes reports the sizes of the last in-memory build, or of the siblings on disk
+func (h *GoBuild) compressedSizes() []CompressedSize {
+	h.mu.RLock()
+	memory := h.memoryEncoded
+	inMemory := len(h.memoryBytes) > 0
+	h.mu.RUnlock()
+
+	var sizes []CompressedSize
+	if inMemory {
+		for _, a := range memory {
+			sizes = append(sizes, CompressedSize{Label: strings.TrimPrefix(a.Extension, "."), Size: a.Size})
+		}
+		return sizes
+	}
+
+	for _, c := range h.config.Compressors {
+		if info, err := os.Stat(h.FinalOutputPath() + c.Extension()); err == nil {
+			sizes = append(sizes, CompressedSize{Label: strings.TrimPrefix(c.Extension(), "."), Size: info.Size()})
+		}
+	}
+	return sizes
+}
+
+// compressedSiblings returns the sibling names of the output, eg: main.wasm.gz
+func (h *GoBuild) compressedSiblings() []string {
+	var names []string
+	for _, c := range h.config.Compressors {
+		names = append(names, h.outFileName+c.Extension())
+	}
+	return names
+}
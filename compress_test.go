@@ -0,0 +1,148 @@
+package gobuild
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// reverseCompressor is a pluggable encoder used to check non-gzip siblings
+type reverseCompressor struct{ err error }
+
+func (r reverseCompressor) Extension() string { return ".rev" }
+
+func (r reverseCompressor) Compress(data []byte) ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	out := slices.Clone(data)
+	slices.Reverse(out)
+	return out, nil
+}
+
+func gunzip(t *testing.T, data []byte) []byte {
+	t.Helper()
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Invalid gzip data: %v", err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Invalid gzip data: %v", err)
+	}
+	return out
+}
+
+func TestCompressedSiblings(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, fakeBuildScript)
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     tempDir,
+		Compressors:               []Compressor{GzipCompressor(gzip.BestCompression), reverseCompressor{}},
+	})
+
+	result, err := gb.CompileProgramResult()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if len(result.Compressed) != 2 {
+		t.Fatalf("Expected one artifact per compressor, got %+v", result.Compressed)
+	}
+	gz := result.Compressed[0]
+	if gz.Path != gb.FinalOutputPath()+".gz" || gz.Bytes != nil {
+		t.Errorf("Expected the .gz sibling path without bytes for disk builds, got %+v", gz)
+	}
+	data, err := os.ReadFile(gz.Path)
+	if err != nil || int64(len(data)) != gz.Size {
+		t.Fatalf("Expected the .gz sibling on disk, got %d bytes %v", len(data), err)
+	}
+	if string(gunzip(t, data)) != "fake binary\n" {
+		t.Error("Expected the sibling to decode to the output")
+	}
+	if rev, _ := os.ReadFile(gb.FinalOutputPath() + ".rev"); string(rev) != "\nyranib ekaf" {
+		t.Errorf("Expected the pluggable sibling, got %q", rev)
+	}
+
+	unobserved := gb.UnobservedFiles()
+	if !slices.Contains(unobserved, "main.wasm.gz") || !slices.Contains(unobserved, "main.wasm.rev") {
+		t.Errorf("Expected the siblings in UnobservedFiles, got %v", unobserved)
+	}
+	if size := gb.BinarySize(); !strings.Contains(size, "(gz ") || !strings.Contains(size, ", rev 0.0 KB)") {
+		t.Errorf("Expected compressed sizes in BinarySize, got %q", size)
+	}
+
+	memory, err := gb.CompileToMemoryResult()
+	if err != nil {
+		t.Fatalf("Memory build failed: %v", err)
+	}
+	if len(memory.Compressed) != 2 || memory.Compressed[0].Path != "" {
+		t.Fatalf("Expected in-memory compressed artifacts, got %+v", memory.Compressed)
+	}
+	if string(gunzip(t, memory.Compressed[0].Bytes)) != string(memory.Bytes) {
+		t.Error("Expected the compressed bytes of the in-memory artifact")
+	}
+}
+
+func TestCompressorFailure(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, fakeBuildScript)
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		OutFolderRelativePath:     tempDir,
+		Compressors:               []Compressor{reverseCompressor{err: errors.New("encoder crashed")}},
+	})
+
+	err := gb.CompileProgram()
+	if !errors.Is(err, ErrPostProcess) || !strings.Contains(err.Error(), ".rev compressor") {
+		t.Errorf("Expected the compressor failure, got %v", err)
+	}
+	if _, err := os.Stat(gb.FinalOutputPath()); !os.IsNotExist(err) {
+		t.Error("Expected nothing to be promoted when a compressor fails")
+	}
+}
+
+func TestCompressedSiblingFailureKeepsOutput(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, fakeBuildScript)
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     tempDir,
+		Compressors:               []Compressor{GzipCompressor(gzip.BestSpeed), reverseCompressor{}},
+	})
+
+	// a directory in place of main.wasm.gz makes the sibling write fail
+	writeFiles(t, tempDir, map[string]string{"main.wasm": "previous", "main.wasm.rev": "suoiverp", "main.wasm.gz/keep": ""})
+
+	err := gb.CompileProgram()
+	if !errors.Is(err, ErrOutputPromotion) {
+		t.Fatalf("Expected ErrOutputPromotion, got %v", err)
+	}
+	if data, _ := os.ReadFile(gb.FinalOutputPath()); string(data) != "previous" {
+		t.Errorf("Expected the output to stay untouched, got %q", data)
+	}
+	if data, _ := os.ReadFile(gb.FinalOutputPath() + ".rev"); string(data) != "suoiverp" {
+		t.Errorf("Expected the previous siblings to stay in place, got %q", data)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(tempDir, "main*tmp*")); len(matches) != 0 {
+		t.Errorf("Expected staged and temp files to be removed, found %v", matches)
+	}
+}
@@ -1,6 +1,7 @@
 package gobuild
 
 import (
+	"io"
 	"time"
 )
 
@@ -21,4 +22,21 @@ type Config struct {
 	Callback                  CompileCallback      // optional callback for async compilation
 	Timeout                   time.Duration        // max compilation time, defaults to 5 seconds if not set
 	Env                       []string             // environment variables, eg: []string{"GOOS=js", "GOARCH=wasm"}
+	StreamOutput              bool                 // send each toolchain output line to Logger while building
+	OutputWriter              io.Writer            // optional live copy of the raw toolchain output
+	HistorySize               int                  // builds kept by History(), defaults to 20 if not set
+	Debounce                  time.Duration        // quiet period before a RequestBuild runs, defaults to 100ms if not set
+	ConcurrencyPolicy         ConcurrencyPolicy    // what a build does while another one runs, defaults to CancelPrevious
+	KillGracePeriod           time.Duration        // time between SIGTERM and SIGKILL on cancel/timeout, defaults to 2 seconds if not set
+	BuildCacheDir             string               // opt-in artifact cache skipping the toolchain when inputs are unchanged, eg: .gobuild/cache (relative to AppRootDir)
+	BuildCacheMaxBytes        int64                // size bound of BuildCacheDir, defaults to 512 MB if not set
+	WatchInterval             time.Duration        // polling period of Watch, defaults to 300ms if not set
+	IsolatedDir               string               // private GOCACHE, GOTMPDIR and GOPATH folders created on demand, eg: .gobuild (relative to AppRootDir)
+	TinyGo                    *TinyGoOptions       // typed tinygo flags, requires Command "tinygo", eg: &TinyGoOptions{Target: "wasm", Opt: "z"}
+	PostProcessors            []PostProcessor      // rewrite the artifact in order before promotion, eg: wasm-opt
+	Compressors               []Compressor         // precompressed siblings of the output, eg: []Compressor{GzipCompressor(gzip.BestCompression)}
+	ValidateOutput            bool                 // also reject truncated or corrupt ELF/Mach-O/PE artifacts, wasm is always checked
+	SkipWasmValidation        bool                 // promote wasm artifacts without parsing their header and sections
+	Profile                   bool                 // run compile and link through -toolexec and report BuildResult.Profile (go only)
+	SkipIdenticalOutput       bool                 // keep the final file and its mtime when the new build is byte-identical, see BuildResult.Unchanged
 }
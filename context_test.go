@@ -0,0 +1,79 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompileProgramContextCanceledByCaller(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, "exec sleep 5")
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "ctxapp",
+		OutFolderRelativePath:     tempDir,
+		Timeout:                   10 * time.Second,
+	})
+
+	errShutdown := errors.New("server shutting down")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(200*time.Millisecond, func() { cancel(errShutdown) })
+
+	start := time.Now()
+	err := gb.CompileProgramContext(ctx)
+
+	if !errors.Is(err, ErrCanceled) {
+		t.Errorf("Expected ErrCanceled, got %v", err)
+	}
+	if !errors.Is(err, errShutdown) {
+		t.Errorf("Expected the caller's cause to be kept, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Caller cancellation should stop the build early, took %v", elapsed)
+	}
+}
+
+func TestCompileToMemoryContextDeadline(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, "exec sleep 5")
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "ctxapp",
+		Timeout:                   10 * time.Second,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := gb.CompileToMemoryContext(ctx)
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the caller's deadline as cause, got %v", err)
+	}
+}
+
+func TestCompileProgramContextConfigTimeoutStillApplies(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, "exec sleep 5")
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "ctxapp",
+		OutFolderRelativePath:     tempDir,
+		Timeout:                   100 * time.Millisecond,
+	})
+
+	err := gb.CompileProgramContext(context.Background())
+	if !errors.Is(err, ErrTimeout) || errors.Is(err, ErrCanceled) {
+		t.Errorf("Expected ErrTimeout only, got %v", err)
+	}
+}
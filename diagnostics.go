@@ -0,0 +1,93 @@
+package gobuild
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity levels reported by the toolchain
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic is a single file:line message reported by go or tinygo
+type Diagnostic struct {
+	File     string // relative to Config.AppRootDir when possible, eg: web/main.go
+	Line     int
+	Column   int    // 0 when the toolchain does not report it
+	Package  string // from the "# pkg" header, eg: command-line-arguments
+	Message  string // eg: undefined: x
+	Severity string // SeverityError or SeverityWarning
+}
+
+// diagnosticLine matches "file.go:line:col: message" and "file.go:line: message"
+var diagnosticLine = regexp.MustCompile(`^(.+?\.(?:go|s|c|h|cc|cpp)):(\d+)(?::(\d+))?: (.*)$`)
+
+// parseDiagnostics extracts the diagnostics from go/tinygo output
+// Indented lines following a diagnostic are appended to its message (eg: have/want hints)
+func (h *GoBuild) parseDiagnostics(output string) []Diagnostic {
+	var diags []Diagnostic
+	pkg := ""
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if strings.HasPrefix(line, "# ") {
+			pkg = strings.TrimSpace(line[2:])
+			continue
+		}
+
+		if strings.HasPrefix(line, "\t") && len(diags) > 0 {
+			diags[len(diags)-1].Message += "\n" + strings.TrimSpace(line)
+			continue
+		}
+
+		m := diagnosticLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		d := Diagnostic{
+			File:     h.relativeToRoot(m[1]),
+			Package:  pkg,
+			Message:  m[4],
+			Severity: SeverityError,
+		}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+
+		if msg, ok := strings.CutPrefix(d.Message, "warning: "); ok {
+			d.Message = msg
+			d.Severity = SeverityWarning
+		} else if msg, ok := strings.CutPrefix(d.Message, "error: "); ok {
+			d.Message = msg
+		}
+
+		diags = append(diags, d)
+	}
+
+	return diags
+}
+
+// relativeToRoot returns file relative to Config.AppRootDir
+// The toolchain runs inside AppRootDir, so relative paths are already rooted there
+// Absolute paths outside AppRootDir are returned unchanged
+func (h *GoBuild) relativeToRoot(file string) string {
+	if !filepath.IsAbs(file) {
+		return filepath.Clean(file)
+	}
+
+	root := h.config.AppRootDir
+	if root == "" {
+		return file
+	}
+
+	rel, err := filepath.Rel(root, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return file
+	}
+	return rel
+}
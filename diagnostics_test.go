@@ -0,0 +1,109 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseDiagnosticsGoOutput(t *testing.T) {
+	gb := New(&Config{AppRootDir: "/project"})
+
+	output := `# command-line-arguments
+./web/main.go:6:17: cannot use 1 (untyped int constant) as string value in variable declaration
+/project/web/util.go:7:2: undefined: x
+/other/lib.go:3:1: missing return
+	have ()
+	want (int)
+`
+	diags := gb.parseDiagnostics(output)
+
+	expected := []Diagnostic{
+		{File: "web/main.go", Line: 6, Column: 17, Package: "command-line-arguments", Message: "cannot use 1 (untyped int constant) as string value in variable declaration", Severity: SeverityError},
+		{File: "web/util.go", Line: 7, Column: 2, Package: "command-line-arguments", Message: "undefined: x", Severity: SeverityError},
+		{File: "/other/lib.go", Line: 3, Column: 1, Package: "command-line-arguments", Message: "missing return\nhave ()\nwant (int)", Severity: SeverityError},
+	}
+
+	if len(diags) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %d: %+v", len(expected), len(diags), diags)
+	}
+
+	for i, d := range diags {
+		if d != expected[i] {
+			t.Errorf("Diagnostic %d:\nexpected %+v\ngot      %+v", i, expected[i], d)
+		}
+	}
+}
+
+func TestParseDiagnosticsTinyGoOutput(t *testing.T) {
+	gb := New(&Config{AppRootDir: "/project"})
+
+	output := `# example.com/app/web
+web/main.go:12: error: undefined: missing
+web/main.go:20:4: warning: unused variable
+error: failed to build`
+
+	diags := gb.parseDiagnostics(output)
+	if len(diags) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %d: %+v", len(diags), diags)
+	}
+
+	if d := diags[0]; d.Line != 12 || d.Column != 0 || d.Message != "undefined: missing" || d.Severity != SeverityError || d.Package != "example.com/app/web" {
+		t.Errorf("Unexpected first diagnostic: %+v", d)
+	}
+
+	if d := diags[1]; d.Severity != SeverityWarning || d.Message != "unused variable" {
+		t.Errorf("Unexpected second diagnostic: %+v", d)
+	}
+}
+
+func TestCompileProgramReturnsBuildError(t *testing.T) {
+	tempDir := t.TempDir()
+
+	errorContent := `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println(undefinedVariable)
+}
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "main.go"), []byte(errorContent), 0644); err != nil {
+		t.Fatalf("Failed to create main.go: %v", err)
+	}
+
+	config := &Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "diagapp",
+		Extension:                 getExecutableExtension(),
+		OutFolderRelativePath:     tempDir,
+		Timeout:                   30 * time.Second,
+	}
+	gb := New(config)
+
+	check := func(name string, err error) {
+		var buildErr *BuildError
+		if !errors.As(err, &buildErr) {
+			t.Fatalf("%s: expected *BuildError, got %T: %v", name, err, err)
+		}
+		if buildErr.Output == "" {
+			t.Errorf("%s: expected raw output to be kept", name)
+		}
+		if len(buildErr.Diagnostics) != 1 {
+			t.Fatalf("%s: expected 1 diagnostic, got %+v", name, buildErr.Diagnostics)
+		}
+		d := buildErr.Diagnostics[0]
+		if d.File != "main.go" || d.Line != 6 || d.Column != 14 || d.Message != "undefined: undefinedVariable" {
+			t.Errorf("%s: unexpected diagnostic %+v", name, d)
+		}
+	}
+
+	check("CompileProgram", gb.CompileProgram())
+
+	_, err := gb.CompileToMemory()
+	check("CompileToMemory", err)
+}
@@ -0,0 +1,93 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Build failure kinds, match them with errors.Is
+// eg: if errors.Is(err, gobuild.ErrCanceled) { return }
+var (
+	ErrCompile           = errors.New("compilation failed")
+	ErrTimeout           = errors.New("compilation timed out")
+	ErrCanceled          = errors.New("compilation canceled")
+	ErrToolchainNotFound = errors.New("toolchain not found")
+	ErrOutputPromotion   = errors.New("output promotion failed")
+	ErrBusy              = errors.New("compilation already in progress") // see DropIfBusy
+	ErrInvalidArguments  = errors.New("invalid build arguments")         // eg: -race with tinygo
+	ErrPostProcess       = errors.New("post-processing failed")          // see PostProcessError
+	ErrInvalidArtifact   = errors.New("invalid artifact")                // see ArtifactError
+)
+
+// BuildError is returned when a build does not produce its final output
+// Kind is one of the Err* sentinels, Output keeps the raw toolchain output
+type BuildError struct {
+	Kind        error  // eg: ErrCompile, ErrTimeout
+	Err         error  // underlying error, eg: exit status 1
+	Output      string // raw combined toolchain output
+	Diagnostics []Diagnostic
+}
+
+func (e *BuildError) Error() string {
+	msg := ErrCompile.Error()
+	if e.Kind != nil {
+		msg = e.Kind.Error()
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if out := strings.TrimSpace(e.Output); out != "" {
+		msg += "\n" + out
+	}
+	return msg
+}
+
+// Unwrap exposes both Kind and Err to errors.Is and errors.As
+func (e *BuildError) Unwrap() []error {
+	var errs []error
+	for _, err := range []error{e.Kind, e.Err} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// errorKind classifies a failed toolchain run
+// A stopped ctx wins over the process error, eg: "signal: killed" after Cancel()
+func errorKind(ctx context.Context, err error) error {
+	if kind := stopKind(ctx); kind != nil {
+		return kind
+	}
+
+	var execErr *exec.Error
+	if errors.Is(err, exec.ErrNotFound) || errors.As(err, &execErr) {
+		return ErrToolchainNotFound
+	}
+
+	// an absolute Command skips the PATH lookup and fails to start instead,
+	// eg: "fork/exec /opt/sdk/go1.99/bin/go: no such file or directory"
+	// a started process never returns a *fs.PathError, a missing Dir names an existing binary
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) && errors.Is(err, fs.ErrNotExist) {
+		if _, statErr := os.Stat(pathErr.Path); errors.Is(statErr, fs.ErrNotExist) {
+			return ErrToolchainNotFound
+		}
+	}
+	return ErrCompile
+}
+
+// stopKind returns ErrTimeout or ErrCanceled once ctx is done, nil before
+func stopKind(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	if errors.Is(context.Cause(ctx), ErrTimeout) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	return ErrCanceled
+}
@@ -0,0 +1,176 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// writeFakeToolchain creates a shell script usable as Config.Command
+// `list` is answered by the real go toolchain, script handles everything else
+func writeFakeToolchain(t *testing.T, dir, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake toolchain scripts require a unix shell")
+	}
+
+	toolPath := filepath.Join(dir, "fake-toolchain")
+	delegate := `if [ "$1" = "list" ]; then exec go "$@"; fi` + "\n"
+	if err := os.WriteFile(toolPath, []byte("#!/bin/sh\n"+delegate+script+"\n"), 0755); err != nil {
+		t.Fatalf("Failed to create fake toolchain: %v", err)
+	}
+	return toolPath
+}
+
+// fakeBuildScript writes a placeholder binary to the -o destination, like a very fast go build
+const fakeBuildScript = `while [ $# -gt 0 ]; do
+	if [ "$1" = "-o" ]; then shift; out="$1"; fi
+	shift
+done
+echo "fake binary" > "$out"`
+
+func TestErrorKindToolchainNotFound(t *testing.T) {
+	gb := New(&Config{
+		Command:                   "nonexistentcommand",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "test",
+		OutFolderRelativePath:     t.TempDir(),
+	})
+
+	if err := gb.CompileProgram(); !errors.Is(err, ErrToolchainNotFound) {
+		t.Errorf("CompileProgram: expected ErrToolchainNotFound, got %v", err)
+	}
+
+	if _, err := gb.CompileToMemory(); !errors.Is(err, ErrToolchainNotFound) {
+		t.Errorf("CompileToMemory: expected ErrToolchainNotFound, got %v", err)
+	}
+}
+
+func TestErrorKindToolchainNotFoundAbsolutePath(t *testing.T) {
+	tempDir := t.TempDir()
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   filepath.Join(tempDir, "sdk", "go1.99", "bin", "go"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "test",
+		OutFolderRelativePath:     tempDir,
+	})
+
+	if err := gb.CompileProgram(); !errors.Is(err, ErrToolchainNotFound) {
+		t.Errorf("CompileProgram: expected ErrToolchainNotFound, got %v", err)
+	}
+	if _, err := gb.CompileToMemory(); !errors.Is(err, ErrToolchainNotFound) {
+		t.Errorf("CompileToMemory: expected ErrToolchainNotFound, got %v", err)
+	}
+
+	// a missing working directory is not a missing toolchain
+	gb = New(&Config{
+		AppRootDir:                filepath.Join(tempDir, "missing"),
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "test",
+		OutFolderRelativePath:     tempDir,
+	})
+	if err := gb.CompileProgram(); errors.Is(err, ErrToolchainNotFound) {
+		t.Errorf("Expected a missing AppRootDir to stay a compile error, got %v", err)
+	}
+}
+
+func TestErrorKindCompile(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, `echo "main.go:1:1: expected 'package'" >&2; exit 1`)
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "test",
+		OutFolderRelativePath:     tempDir,
+	})
+
+	err := gb.CompileProgram()
+	if !errors.Is(err, ErrCompile) {
+		t.Errorf("CompileProgram: expected ErrCompile, got %v", err)
+	}
+	if errors.Is(err, ErrCanceled) || errors.Is(err, ErrTimeout) {
+		t.Errorf("CompileProgram: compile error must not match other kinds: %v", err)
+	}
+
+	if _, err := gb.CompileToMemory(); !errors.Is(err, ErrCompile) {
+		t.Errorf("CompileToMemory: expected ErrCompile, got %v", err)
+	}
+}
+
+func TestErrorKindTimeout(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, "exec sleep 5")
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "test",
+		OutFolderRelativePath:     tempDir,
+		Timeout:                   100 * time.Millisecond,
+	})
+
+	if err := gb.CompileProgram(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("CompileProgram: expected ErrTimeout, got %v", err)
+	}
+
+	if _, err := gb.CompileToMemory(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("CompileToMemory: expected ErrTimeout, got %v", err)
+	}
+}
+
+func TestErrorKindCanceled(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, "exec sleep 5")
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "test",
+		OutFolderRelativePath:     tempDir,
+		Timeout:                   10 * time.Second,
+	})
+
+	errs := make(chan error, 2)
+	go func() { errs <- gb.CompileProgram() }()
+	go func() {
+		_, err := gb.CompileToMemory()
+		errs <- err
+	}()
+
+	// The second call cancels the first one, Cancel() stops the remaining one
+	time.Sleep(200 * time.Millisecond)
+	gb.Cancel()
+
+	for range 2 {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, ErrCanceled) {
+				t.Errorf("Expected ErrCanceled, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Canceled compilation did not return")
+		}
+	}
+}
+
+func TestErrorKindOutputPromotion(t *testing.T) {
+	gb := New(&Config{
+		OutName:               "testapp",
+		Extension:             ".exe",
+		OutFolderRelativePath: t.TempDir(),
+	})
+
+	err := gb.RenameOutputFile()
+	if !errors.Is(err, ErrOutputPromotion) {
+		t.Errorf("Expected ErrOutputPromotion, got %v", err)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected underlying os.ErrNotExist to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package gobuild
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BuildEventKind names a step of a disk build, eg: EventPackageCompiled
+type BuildEventKind string
+
+// Build event kinds delivered to Subscribe
+const (
+	EventOutput          BuildEventKind = "output"           // toolchain output, tied to Package when the toolchain reports it
+	EventPackageFailed   BuildEventKind = "package-failed"   // eg: a compile error in Package
+	EventPackageStarted  BuildEventKind = "package-started"  // from the action graph, sent once the toolchain exited
+	EventPackageCompiled BuildEventKind = "package-compiled" // from the action graph, Duration is the compile time
+	EventCacheHit        BuildEventKind = "cache-hit"        // from the action graph, Package was restored from the go build cache
+	EventLinkStarted     BuildEventKind = "link-started"     // from the action graph
+	EventLinkDone        BuildEventKind = "link-done"        // from the action graph, Duration is the link time
+)
+
+// BuildEvent is one step of a disk build reported to Subscribe
+type BuildEvent struct {
+	BuildID  uint64 // same as History()
+	Kind     BuildEventKind
+	Package  string // import path, eg: example.com/app/ui
+	Output   string // EventOutput text
+	Time     time.Time
+	Duration time.Duration
+}
+
+// Subscribe calls fn for every event of the following disk builds and returns its unsubscribe func
+// With go 1.24+ output arrives as `go build -json` events tied to their package, older
+// toolchains and tinygo send plain output lines. Package and link events come from
+// -debug-actiongraph (go only) after the toolchain exits, ordered by their recorded time
+// fn runs on the build goroutine, it must not block
+func (h *GoBuild) Subscribe(fn func(BuildEvent)) (unsubscribe func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers == nil {
+		h.subscribers = map[uint64]func(BuildEvent){}
+	}
+	h.lastSubscriber++
+	id := h.lastSubscriber
+	h.subscribers[id] = fn
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers, id)
+	}
+}
+
+// emit delivers ev to the current subscribers
+func (h *GoBuild) emit(ev BuildEvent) {
+	h.mu.RLock()
+	subscribers := make([]func(BuildEvent), 0, len(h.subscribers))
+	for _, fn := range h.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	h.mu.RUnlock()
+
+	for _, fn := range subscribers {
+		fn(ev)
+	}
+}
+
+// eventStream turns the output of one build into BuildEvents
+type eventStream struct {
+	h         *GoBuild
+	id        uint64
+	json      bool   // go build -json is available
+	graphFile string // -debug-actiongraph destination, "" without it
+	stdout    *outputWriter
+}
+
+// startEvents prepares comp for event reporting, nil when nobody subscribed
+// It returns the flags to add after "build"
+func (h *GoBuild) startEvents(ctx context.Context, comp *compilation) (*eventStream, []string) {
+	h.mu.RLock()
+	subscribed := len(h.subscribers) > 0
+	h.mu.RUnlock()
+	if !subscribed {
+		return nil, nil
+	}
+
+	ev := &eventStream{h: h, id: comp.id}
+	if h.isTinyGo() {
+		return ev, nil
+	}
+
+	var flags []string
+	if minor := h.goMinorVersion(ctx); minor >= 24 {
+		ev.json = true
+		flags = append(flags, "-json")
+	}
+	if f, err := os.CreateTemp("", "gobuild-actiongraph-*.json"); err == nil {
+		f.Close()
+		ev.graphFile = f.Name()
+		flags = append(flags, "-debug-actiongraph="+ev.graphFile)
+	}
+	return ev, flags
+}
+
+// attach routes the output of cmd through the stream, out keeps the plain text
+func (e *eventStream) attach(cmd *exec.Cmd, out *outputWriter) {
+	if e == nil {
+		return
+	}
+
+	if !e.json {
+		// every plain line becomes an EventOutput next to the Logger
+		logger := out.logger
+		out.logger = func(message ...any) {
+			if logger != nil {
+				logger(message...)
+			}
+			e.send(BuildEvent{Kind: EventOutput, Output: message[0].(string)})
+		}
+		return
+	}
+
+	// stdout carries JSON lines, stderr stays plain, eg: go: errors before building
+	e.stdout = &outputWriter{logger: func(message ...any) { e.decode(message[0].(string), out) }}
+	cmd.Stdout = e.stdout
+}
+
+// decode handles one go build -json line, its output text goes back to out
+func (e *eventStream) decode(line string, out *outputWriter) {
+	var ev struct {
+		ImportPath string
+		Action     string
+		Output     string
+	}
+	if json.Unmarshal([]byte(line), &ev) != nil {
+		out.Write([]byte(line + "\n"))
+		e.send(BuildEvent{Kind: EventOutput, Output: line})
+		return
+	}
+
+	switch ev.Action {
+	case "build-output":
+		out.Write([]byte(ev.Output))
+		e.send(BuildEvent{Kind: EventOutput, Package: ev.ImportPath, Output: strings.TrimRight(ev.Output, "\r\n")})
+	case "build-fail":
+		e.send(BuildEvent{Kind: EventPackageFailed, Package: ev.ImportPath})
+	}
+}
+
+// finish flushes pending JSON and sends the action graph events
+func (e *eventStream) finish() {
+	if e == nil {
+		return
+	}
+	if e.stdout != nil {
+		e.stdout.Flush()
+	}
+	if e.graphFile == "" {
+		return
+	}
+	defer os.Remove(e.graphFile)
+
+	data, err := os.ReadFile(e.graphFile)
+	if err != nil {
+		return
+	}
+	var actions []struct {
+		Mode      string
+		Package   string
+		BuildID   string
+		Cmd       []string
+		TimeStart time.Time
+		TimeDone  time.Time
+	}
+	if json.Unmarshal(data, &actions) != nil {
+		return
+	}
+
+	var events []BuildEvent
+	for _, a := range actions {
+		took := a.TimeDone.Sub(a.TimeStart)
+		switch {
+		case a.Mode == "build" && len(a.Cmd) == 0 && a.BuildID != "":
+			events = append(events, BuildEvent{Kind: EventCacheHit, Package: a.Package, Time: a.TimeDone})
+		case a.Mode == "build" && len(a.Cmd) > 0:
+			events = append(events, BuildEvent{Kind: EventPackageStarted, Package: a.Package, Time: a.TimeStart})
+			if a.BuildID != "" {
+				events = append(events, BuildEvent{Kind: EventPackageCompiled, Package: a.Package, Time: a.TimeDone, Duration: took})
+			} else if !e.json {
+				// -json already reported it live
+				events = append(events, BuildEvent{Kind: EventPackageFailed, Package: a.Package, Time: a.TimeDone})
+			}
+		case a.Mode == "link" && len(a.Cmd) > 0:
+			events = append(events, BuildEvent{Kind: EventLinkStarted, Package: a.Package, Time: a.TimeStart})
+			if a.BuildID != "" {
+				events = append(events, BuildEvent{Kind: EventLinkDone, Package: a.Package, Time: a.TimeDone, Duration: took})
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	for _, ev := range events {
+		e.send(ev)
+	}
+}
+
+func (e *eventStream) send(ev BuildEvent) {
+	ev.BuildID = e.id
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	e.h.emit(ev)
+}
+
+// goMinorVersion returns the minor version of the go toolchain, eg: 24 for go1.24.3
+// It is detected once per instance, 0 when unknown, a failed detection is kept as -1
+func (h *GoBuild) goMinorVersion(ctx context.Context) int {
+	h.profilerMu.Lock()
+	defer h.profilerMu.Unlock()
+	if h.goMinor != 0 {
+		return max(h.goMinor, 0)
+	}
+
+	cmd := exec.CommandContext(ctx, h.config.Command, "env", "GOVERSION")
+	cmd.Dir = h.config.AppRootDir
+	cmd.Env = h.buildEnv()
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() == nil {
+			h.goMinor = -1
+		}
+		return 0
+	}
+
+	// eg: go1.24.3, go1.25rc1, devel go1.26-abcdef
+	version := strings.TrimSpace(string(out))
+	_, version, _ = strings.Cut(version, "go1.")
+	end := strings.IndexFunc(version, func(r rune) bool { return r < '0' || r > '9' })
+	if end >= 0 {
+		version = version[:end]
+	}
+	if h.goMinor, _ = strconv.Atoi(version); h.goMinor == 0 {
+		h.goMinor = -1
+		return 0
+	}
+	return h.goMinor
+}
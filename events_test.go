@@ -0,0 +1,173 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventRecorder collects the events of a Subscribe callback
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []BuildEvent
+}
+
+func (r *eventRecorder) record(ev BuildEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+}
+
+// take returns the recorded events and resets the recorder
+func (r *eventRecorder) take() []BuildEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
+
+// findEvent returns the index of the first event of kind for pkg, -1 if missing
+func findEvent(events []BuildEvent, kind BuildEventKind, pkg string) int {
+	for i, ev := range events {
+		if ev.Kind == kind && (pkg == "" || ev.Package == pkg) {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestSubscribeBuildEvents(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"go.mod":      "module example.com/app\n\ngo 1.22\n",
+		"web/main.go": "package main\n\nimport \"example.com/app/ui\"\n\nfunc main() { ui.Render() }\n",
+		"ui/ui.go":    "package ui\n\nfunc Render() { x := 1 }\n",
+	})
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "web/main.go",
+		OutName:                   "main",
+		OutFolderRelativePath:     tempDir,
+		Env:                       []string{"CGO_ENABLED=0"},
+		Timeout:                   2 * time.Minute,
+	})
+	if gb.goMinorVersion(context.Background()) < 24 {
+		t.Skip("go build -json requires go 1.24")
+	}
+
+	rec := &eventRecorder{}
+	unsubscribe := gb.Subscribe(rec.record)
+
+	err := gb.CompileProgram()
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("Expected a *BuildError, got %v", err)
+	}
+	// -json must not change the plain output nor the diagnostics
+	if !strings.Contains(buildErr.Output, "# example.com/app/ui") || len(buildErr.Diagnostics) != 1 {
+		t.Errorf("Expected plain output and diagnostics, got %q %+v", buildErr.Output, buildErr.Diagnostics)
+	}
+
+	events := rec.take()
+	if findEvent(events, EventPackageFailed, "example.com/app/ui") < 0 {
+		t.Errorf("Expected a package-failed event for ui, got %+v", events)
+	}
+	if findEvent(events, EventOutput, "example.com/app/ui") < 0 || !strings.Contains(strings.Join(outputs(events), "\n"), "declared and not used") {
+		t.Errorf("Expected the compile error as output of ui, got %+v", events)
+	}
+
+	writeFiles(t, tempDir, map[string]string{"ui/ui.go": "package ui\n\nfunc Render() {}\n"})
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	events = rec.take()
+	if findEvent(events, EventPackageCompiled, "example.com/app/ui") < 0 {
+		t.Errorf("Expected ui to be compiled, got %+v", events)
+	}
+	if findEvent(events, EventCacheHit, "runtime") < 0 {
+		t.Errorf("Expected runtime from the go build cache, got %+v", events)
+	}
+	started, done := findEvent(events, EventLinkStarted, ""), findEvent(events, EventLinkDone, "")
+	if started < 0 || done < started {
+		t.Errorf("Expected link-started before link-done, got %+v", events)
+	}
+	last := gb.History()[len(gb.History())-1]
+	for _, ev := range events {
+		if ev.BuildID != last.ID {
+			t.Errorf("Expected BuildID %d, got %+v", last.ID, ev)
+			break
+		}
+	}
+
+	unsubscribe()
+	gb.CompileProgram()
+	if events := rec.take(); len(events) != 0 {
+		t.Errorf("Expected no events after unsubscribe, got %d", len(events))
+	}
+}
+
+func TestSubscribePlainOutputFallback(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, "echo compiling pkg\n"+fakeBuildScript)
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "test",
+		OutFolderRelativePath:     tempDir,
+	})
+
+	rec := &eventRecorder{}
+	gb.Subscribe(rec.record)
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	events := rec.take()
+	if len(events) == 0 || events[0].Kind != EventOutput || events[0].Output != "compiling pkg" {
+		t.Errorf("Expected plain output lines as events, got %+v", events)
+	}
+}
+
+func TestGoMinorVersionCachesFailedDetection(t *testing.T) {
+	tempDir := t.TempDir()
+	calls := filepath.Join(tempDir, "calls.log")
+	tool := writeFakeToolchain(t, tempDir, `echo "$@" >> `+calls+`
+if [ "$1" = "env" ]; then exit 1; fi`)
+
+	gb := New(&Config{AppRootDir: tempDir, Command: tool})
+	for i := 0; i < 3; i++ {
+		if minor := gb.goMinorVersion(context.Background()); minor != 0 {
+			t.Fatalf("Expected 0 for an unknown version, got %d", minor)
+		}
+	}
+
+	log, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatalf("Failed to read the toolchain calls: %v", err)
+	}
+	if n := strings.Count(string(log), "env GOVERSION"); n != 1 {
+		t.Errorf("Expected the failed detection to run once, got %d calls", n)
+	}
+}
+
+// outputs returns the text of the output events
+func outputs(events []BuildEvent) []string {
+	var out []string
+	for _, ev := range events {
+		if ev.Kind == EventOutput {
+			out = append(out, ev.Output)
+		}
+	}
+	return out
+}
@@ -1,18 +1,21 @@
 package gobuild
 
 import (
-	"errors"
 	"os"
 	"path"
 )
 
 // UnobservedFiles returns the list of files that should not be tracked by file watchers
-// eg: main.exe, main_temp.exe
+// eg: main.exe, main_temp.exe, wasm_exec.js (GOOS=js builds), main.wasm.gz (Config.Compressors)
 func (h *GoBuild) UnobservedFiles() []string {
-	return []string{
+	files := []string{
 		h.outFileName,
 		h.outTempFileName,
 	}
+	if h.targetsJS() {
+		files = append(files, wasmExecName)
+	}
+	return append(files, h.compressedSiblings()...)
 }
 
 // renameOutputFile renames the temporary output file to the final output file
@@ -27,7 +30,7 @@ func (h *GoBuild) renameOutputFile(tempFileName string) error {
 		if h.config.Logger != nil {
 			h.config.Logger("Rename failed:", err)
 		}
-		return errors.Join(errors.New("renameOutputFile"), err)
+		return &BuildError{Kind: ErrOutputPromotion, Err: err}
 	}
 
 	// fmt.Fprintf(h.config.Logger, "Rename successful\n")
@@ -16,17 +16,25 @@ type Compiler interface {
 	CompileProgram() error
 	FinalOutputPath() string
 	UnobservedFiles() []string
+	InputFiles() ([]string, error)
+	ShouldRecompile(path string) bool
 }
 
 var _ Compiler = (*GoBuild)(nil)
 
 type compilation struct {
-	cmd         *exec.Cmd
-	cancel      context.CancelFunc
-	done        chan error
-	tempFile    string
-	startTime   time.Time
-	memoryBytes []byte // For in-memory compilations
+	id        uint64
+	reason    string // eg: CompileProgram
+	inMemory  bool
+	cmd       *exec.Cmd
+	ctx       context.Context
+	cancel    context.CancelFunc // stops the compilation with ErrCanceled as cause
+	stop      context.CancelFunc // releases the Config.Timeout timer, set once admitted
+	done      chan struct{}      // closed by settle once result and err are set
+	result    *BuildResult
+	err       error
+	tempFile  string
+	startTime time.Time
 }
 
 // GoBuild represents a Go compiler instance
@@ -39,6 +47,19 @@ type GoBuild struct {
 	outFileName     string // eg: main.exe, app
 	outTempFileName string // eg: app_temp.exe
 	binarySizer     *BinarySizer
+	memoryEncoded   []CompressedArtifact
+	memoryBytes     []byte // last CompileToMemory artifact and its memoryEncoded, cleared when a new file is promoted
+	lastID          uint64 // id of the most recent compilation
+	history         *buildHistory
+	scheduler       *scheduler
+	knownInputs     map[string]bool // inputs seen by the last ShouldRecompile, catches deleted files
+	profilerMu      sync.Mutex      // guards profilerPath, goMinor, tinyGoTargets and wasmExec, held while they are detected
+	profilerPath    string          // -toolexec helper binary, see Config.Profile
+	goMinor         int             // go toolchain minor version, -1 when detection failed, see Subscribe
+	subscribers     map[uint64]func(BuildEvent)
+	lastSubscriber  uint64
+	tinyGoTargets   map[string]tinyGoTarget // tinygo info by -target, see buildContext
+	wasmExec        *wasmExecState          // wasm_exec.js source, see provisionWasmExec
 }
 
 // New creates a new GoBuild instance with the given configuration
@@ -52,10 +73,13 @@ func New(c *Config) *GoBuild {
 		config:          c,
 		outFileName:     c.OutName + c.Extension,
 		outTempFileName: c.OutName + "_temp" + c.Extension,
+		history:         newBuildHistory(c.HistorySize),
+		scheduler:       &scheduler{},
 	}
 
 	// Initialize binary sizer with getBinaryBytes method
 	h.binarySizer = NewBinarySizer(h.getBinaryBytes)
+	h.binarySizer.SetCompressedSizes(h.compressedSizes)
 	if c.Logger != nil {
 		h.binarySizer.SetLog(c.Logger)
 	}
@@ -66,77 +90,129 @@ func New(c *Config) *GoBuild {
 // CompileProgram compiles the Go program
 // If a callback is configured, it runs asynchronously and returns immediately
 // Otherwise, it runs synchronously and returns the compilation result
-// Thread-safe: cancels any previous compilation automatically
+// Thread-safe: follows Config.ConcurrencyPolicy, by default cancels any previous compilation
 func (h *GoBuild) CompileProgram() error {
-	h.mu.Lock()
+	return h.CompileProgramContext(context.Background())
+}
 
-	// Cancel any active compilation
-	if h.active != nil {
-		h.active.cancel()
-		// Don't wait for it to finish, just move on
-		h.active = nil
+// CompileProgramContext is CompileProgram bound to ctx, eg: a request or shutdown context
+// The build stops when ctx is done or Config.Timeout expires, whichever comes first
+func (h *GoBuild) CompileProgramContext(ctx context.Context) error {
+	// If callback is defined, run asynchronously
+	if h.config.Callback != nil {
+		h.start(ctx, "CompileProgram")
+		return nil
 	}
 
-	// Create new compilation context
-	ctx, cancel := context.WithTimeout(context.Background(), h.config.Timeout)
+	_, err := h.compileProgramResult(ctx)
+	return err
+}
 
-	// Generate unique temp file name to avoid conflicts
-	tempFileName := fmt.Sprintf("%s_temp_%d%s",
-		h.config.OutName,
-		time.Now().UnixNano(),
-		h.config.Extension)
+// CompileProgramResult compiles the Go program synchronously and reports the build details
+// eg: duration, size and hash of the promoted output. Config.Callback is not used
+// Thread-safe: follows Config.ConcurrencyPolicy, by default cancels any previous compilation
+func (h *GoBuild) CompileProgramResult() (*BuildResult, error) {
+	return h.compileProgramResult(context.Background())
+}
 
-	comp := &compilation{
-		cancel:    cancel,
-		done:      make(chan error, 1),
-		tempFile:  tempFileName,
-		startTime: time.Now(),
-	}
+func (h *GoBuild) compileProgramResult(parent context.Context) (*BuildResult, error) {
+	comp := h.newCompilation(parent, h.uniqueTempFileName(), "CompileProgram", false)
+	return h.run(comp, h.compileSync)
+}
 
-	h.active = comp
-	h.mu.Unlock()
+// newCompilation prepares a compilation writing to tempFile, it does not start it yet
+// reason is kept in the build history, eg: CompileProgram
+// Its context carries ErrCanceled as cause when it is stopped by us
+func (h *GoBuild) newCompilation(parent context.Context, tempFile, reason string, inMemory bool) *compilation {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	// If callback is defined, run asynchronously
-	if h.config.Callback != nil {
-		go func() {
-			err := h.compileSync(ctx, comp)
-			h.config.Callback(err)
-
-			// Clean up active compilation
-			h.mu.Lock()
-			if h.active == comp {
-				h.active = nil
-			}
-			h.mu.Unlock()
-		}()
-		return nil
+	// Create new compilation context
+	ctx, cancelCause := context.WithCancelCause(parent)
+
+	h.lastID++
+	return &compilation{
+		id:       h.lastID,
+		reason:   reason,
+		inMemory: inMemory,
+		ctx:      ctx,
+		cancel:   func() { cancelCause(ErrCanceled) },
+		done:     make(chan struct{}),
+		tempFile: tempFile,
 	}
+}
 
-	// Run synchronously
-	err := h.compileSync(ctx, comp)
+// run admits comp following Config.ConcurrencyPolicy, compiles it and records the outcome
+// A joined compilation returns the outcome of the in-flight one instead
+func (h *GoBuild) run(comp *compilation, compile func(context.Context, *compilation) (*BuildResult, error)) (*BuildResult, error) {
+	joined, err := h.acquire(comp)
+	if joined != nil {
+		<-joined.done
+		h.settle(comp, joined.result, joined.err)
+		return joined.result, joined.err
+	}
+	if err != nil {
+		h.settle(comp, nil, err)
+		return nil, err
+	}
+
+	result, err := compile(comp.ctx, comp)
+	h.finish(comp, result, err)
+
+	return result, err
+}
+
+// finish records the outcome of comp and clears it from the active slot
+// unless a newer compilation replaced it
+func (h *GoBuild) finish(comp *compilation, result *BuildResult, err error) {
+	h.record(comp, result, err)
 
-	// Clean up
 	h.mu.Lock()
 	if h.active == comp {
 		h.active = nil
 	}
 	h.mu.Unlock()
 
-	return err
+	h.settle(comp, result, err)
+}
+
+// settle stores the outcome of comp, releases its context and closes done
+func (h *GoBuild) settle(comp *compilation, result *BuildResult, err error) {
+	comp.cancel()
+	if comp.stop != nil {
+		comp.stop()
+	}
+
+	comp.result, comp.err = result, err
+	close(comp.done)
+}
+
+// uniqueTempFileName generates unique temp file name to avoid conflicts
+// eg: app_temp_1700000000000000000.exe
+func (h *GoBuild) uniqueTempFileName() string {
+	return fmt.Sprintf("%s_temp_%d%s",
+		h.config.OutName,
+		time.Now().UnixNano(),
+		h.config.Extension)
 }
 
 // Cancel cancels any active compilation
+// It returns once the toolchain process has exited and its temp file is removed
 func (h *GoBuild) Cancel() error {
 	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	if h.active != nil {
-		h.active.cancel()
+	comp := h.active
+	if comp != nil {
+		comp.cancel()
 		h.active = nil
-		return nil
+	}
+	h.mu.Unlock()
+
+	if comp == nil {
+		return nil // No active compilation to cancel
 	}
 
-	return nil // No active compilation to cancel
+	<-comp.done
+	return nil
 }
 
 // IsCompiling returns true if there's an active compilation
@@ -186,8 +262,8 @@ func (h *GoBuild) FinalOutputPath() string {
 func (h *GoBuild) getBinaryBytes() []byte {
 	// Check if there's an in-memory compiled binary
 	h.mu.RLock()
-	if h.active != nil && len(h.active.memoryBytes) > 0 {
-		memBytes := h.active.memoryBytes
+	if len(h.memoryBytes) > 0 {
+		memBytes := h.memoryBytes
 		h.mu.RUnlock()
 		return memBytes
 	}
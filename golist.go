@@ -0,0 +1,251 @@
+package gobuild
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// listedPackage is the subset of `go list -json` output used by gobuild
+type listedPackage struct {
+	ImportPath    string
+	Name          string
+	Dir           string
+	Standard      bool
+	GoFiles       []string
+	CgoFiles      []string
+	CFiles        []string
+	CXXFiles      []string
+	HFiles        []string
+	SFiles        []string
+	SysoFiles     []string
+	EmbedFiles    []string
+	EmbedPatterns []string
+	Module        *struct {
+		Path    string
+		Dir     string
+		GoMod   string
+		Main    bool
+		Replace *struct{ Dir string }
+	}
+}
+
+// sourceFiles returns the absolute paths of every file compiled or embedded by p
+func (p listedPackage) sourceFiles() []string {
+	var files []string
+	for _, group := range [][]string{p.GoFiles, p.CgoFiles, p.CFiles, p.CXXFiles, p.HFiles, p.SFiles, p.SysoFiles, p.EmbedFiles} {
+		for _, name := range group {
+			files = append(files, filepath.Join(p.Dir, name))
+		}
+	}
+	return files
+}
+
+// embedGlobs returns the globs whose matches decide the embedded files of p, eg: /app/web/*.txt
+// Directories matched by a pattern are embedded recursively, each one below gets a "<dir>/*" glob
+// so added assets are noticed, EmbedFiles only lists the files present at listing time
+func (p listedPackage) embedGlobs() []string {
+	seen := map[string]bool{}
+	var globs []string
+	add := func(glob string) {
+		if !seen[glob] {
+			seen[glob] = true
+			globs = append(globs, glob)
+		}
+	}
+
+	for _, pattern := range p.EmbedPatterns {
+		full := filepath.Join(p.Dir, filepath.FromSlash(strings.TrimPrefix(pattern, "all:")))
+		if isGlob(full) {
+			add(full)
+		}
+		matches, _ := filepath.Glob(full)
+		for _, match := range matches {
+			filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err == nil && d.IsDir() {
+					add(filepath.Join(path, "*"))
+				}
+				return nil
+			})
+		}
+	}
+	return globs
+}
+
+// isGlob reports whether the last element of path is a pattern, see embedGlobs
+func isGlob(path string) bool {
+	return strings.ContainsAny(filepath.Base(path), "*?[")
+}
+
+// local reports whether p can change without a go.sum update
+// Packages from the module cache are immutable and pinned by go.sum
+func (p listedPackage) local() bool {
+	if p.Standard {
+		return false
+	}
+	return p.Module == nil || p.Module.Main || p.Module.Replace != nil
+}
+
+// listPackages runs `<Command> list -e -deps -json` on the main input with Config.Env
+// The configured toolchain answers, eg: go1.22.5 or `tinygo list`, so GOROOT matches the build
+func (h *GoBuild) listPackages(ctx context.Context) ([]listedPackage, error) {
+	args := append([]string{"list", "-e", "-deps", "-json"}, h.listFlags()...)
+	args = append(args, h.config.MainInputFileRelativePath)
+
+	cmd := exec.CommandContext(ctx, h.config.Command, args...)
+	cmd.Dir = h.config.AppRootDir
+	cmd.Env = h.buildEnv()
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, &BuildError{Kind: errorKind(ctx, err), Err: err, Output: stderr.String()}
+	}
+
+	var pkgs []listedPackage
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var p listedPackage
+		if err := dec.Decode(&p); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, p)
+	}
+
+	return pkgs, nil
+}
+
+// InputFiles returns the files that can change the output of this build, sorted
+// Go, cgo and //go:embed files of every local package in the dependency graph plus go.mod/go.sum
+// Packages from GOROOT and the module cache are left out, they only change with go.mod
+// eg: watchers rebuild only when one of these files changes, see UnobservedFiles
+func (h *GoBuild) InputFiles() ([]string, error) {
+	pkgs, err := h.listPackages(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return inputFiles(pkgs), nil
+}
+
+// inputFiles returns the sorted source and module files of the local packages
+func inputFiles(pkgs []listedPackage) []string {
+	seen := map[string]bool{}
+	var files []string
+	for _, p := range pkgs {
+		if !p.local() {
+			continue
+		}
+		for _, file := range p.sourceFiles() {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	files = append(files, moduleFiles(pkgs)...)
+
+	sort.Strings(files)
+	return files
+}
+
+// isTinyGo reports whether Config.Command is the tinygo compiler
+func (h *GoBuild) isTinyGo() bool {
+	name := strings.TrimSuffix(filepath.Base(h.config.Command), ".exe")
+	return name == "tinygo"
+}
+
+// listFlags returns the build flags that change the package graph
+// eg: -tags, -mod, or -target=wasm for tinygo, in the order buildArguments uses
+func (h *GoBuild) listFlags() []string {
+	var flags []string
+	if h.isTinyGo() && h.config.TinyGo != nil && h.config.TinyGo.Target != "" {
+		flags = append(flags, "-target="+h.config.TinyGo.Target)
+	}
+	return append(flags, h.pickFlags("-tags", "-mod", "-modfile", "-pgo", "-target")...)
+}
+
+// pickFlags returns the named value flags of CompilingArguments with their values
+// eg: "-tags", "wasm" or "-tags=wasm"
+func (h *GoBuild) pickFlags(names ...string) []string {
+	if h.config.CompilingArguments == nil {
+		return nil
+	}
+
+	var flags []string
+	args := h.config.CompilingArguments()
+	for i := 0; i < len(args); i++ {
+		name, _, hasValue := strings.Cut(args[i], "=")
+		if !slices.Contains(names, name) {
+			continue
+		}
+		flags = append(flags, args[i])
+		if !hasValue && i+1 < len(args) {
+			flags = append(flags, args[i+1])
+			i++
+		}
+	}
+	return flags
+}
+
+// moduleFiles returns go.mod and go.sum of the modules that can change locally
+// The main module is found by walking up from the main package directory
+func moduleFiles(pkgs []listedPackage) []string {
+	seen := map[string]bool{}
+	var files []string
+
+	add := func(goMod string) {
+		if goMod == "" || seen[goMod] {
+			return
+		}
+		seen[goMod] = true
+		files = append(files, goMod)
+		if sum := filepath.Join(filepath.Dir(goMod), "go.sum"); fileExists(sum) {
+			files = append(files, sum)
+		}
+	}
+
+	for _, p := range pkgs {
+		if !p.local() {
+			continue
+		}
+		if p.Module != nil {
+			add(p.Module.GoMod)
+		} else {
+			add(findGoMod(p.Dir))
+		}
+	}
+	return files
+}
+
+// findGoMod returns the go.mod governing dir, or "" outside a module
+func findGoMod(dir string) string {
+	for {
+		goMod := filepath.Join(dir, "go.mod")
+		if fileExists(goMod) {
+			return goMod
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func fileExists(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && !info.IsDir()
+}
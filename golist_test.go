@@ -0,0 +1,116 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates each name: content pair under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		full := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestInputFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"go.mod":           "module example.com/app\n\ngo 1.22\n",
+		"web/main.go":      "package main\n\nimport _ \"example.com/app/ui\"\n\nfunc main() {}\n",
+		"ui/ui_js.go":      "package ui\n",
+		"ui/ui.go":         "package ui\n\nimport _ \"embed\"\n\n//go:embed index.html\nvar Index string\n",
+		"ui/index.html":    "<html></html>",
+		"server/server.go": "package server\n",
+		"web/main_test.go": "package main\n",
+		"web/notes.txt":    "not an input",
+	})
+
+	config := &Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "web/main.go",
+		OutName:                   "main",
+		OutFolderRelativePath:     filepath.Join(tempDir, "public"),
+	}
+
+	files, err := New(config).InputFiles()
+	if err != nil {
+		t.Fatalf("InputFiles failed: %v", err)
+	}
+
+	abs := func(name string) string { return filepath.Join(tempDir, name) }
+	for _, want := range []string{"go.mod", "web/main.go", "ui/ui.go", "ui/index.html"} {
+		if !slices.Contains(files, abs(want)) {
+			t.Errorf("Expected %s in %v", want, files)
+		}
+	}
+	for _, unwanted := range []string{"ui/ui_js.go", "server/server.go", "web/main_test.go", "web/notes.txt"} {
+		if slices.Contains(files, abs(unwanted)) {
+			t.Errorf("Did not expect %s in %v", unwanted, files)
+		}
+	}
+	if !slices.IsSorted(files) {
+		t.Errorf("Expected sorted files, got %v", files)
+	}
+
+	// Env selects the build constraints, eg: GOOS=js picks ui_js.go
+	config.Env = []string{"GOOS=js", "GOARCH=wasm"}
+	files, err = New(config).InputFiles()
+	if err != nil {
+		t.Fatalf("InputFiles with Env failed: %v", err)
+	}
+	if !slices.Contains(files, abs("ui/ui_js.go")) {
+		t.Errorf("Expected ui/ui_js.go with GOOS=js, got %v", files)
+	}
+}
+
+// writeVersionedToolchain creates a go wrapper reached by absolute path, like go1.22.5
+// Each call appends its arguments to calls before running the real go toolchain
+func writeVersionedToolchain(t *testing.T, dir, calls string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the toolchain wrapper requires a unix shell")
+	}
+
+	versioned := filepath.Join(dir, "sdk", "bin", "go1.99.0")
+	writeFiles(t, dir, map[string]string{"sdk/bin/go1.99.0": "#!/bin/sh\necho \"$@\" >> \"" + calls + "\"\nexec go \"$@\"\n"})
+	if err := os.Chmod(versioned, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return versioned
+}
+
+func TestListUsesConfiguredCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	writeMainGo(t, tempDir, "Hello from versioned toolchain!")
+
+	calls := filepath.Join(tempDir, "calls.log")
+	versioned := writeVersionedToolchain(t, tempDir, calls)
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   versioned,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		OutFolderRelativePath:     tempDir,
+	})
+
+	if _, err := gb.InputFiles(); err != nil {
+		t.Fatalf("InputFiles failed: %v", err)
+	}
+	data, _ := os.ReadFile(calls)
+	if !strings.HasPrefix(string(data), "list ") {
+		t.Errorf("Expected go list to run through Config.Command, got calls %q", data)
+	}
+}
@@ -0,0 +1,73 @@
+package gobuild
+
+import "context"
+
+// BuildHandle tracks a compilation started with Start()
+type BuildHandle struct {
+	comp *compilation
+}
+
+// Start compiles the Go program to disk in the background and returns immediately
+// Config.Callback, when set, is called after the handle is done
+// Thread-safe: follows Config.ConcurrencyPolicy, with JoinInFlight the handle
+// of the in-flight disk build is returned
+func (h *GoBuild) Start() *BuildHandle {
+	return h.start(context.Background(), "Start")
+}
+
+// start runs a disk compilation in its own goroutine
+func (h *GoBuild) start(parent context.Context, reason string) *BuildHandle {
+	comp := h.newCompilation(parent, h.uniqueTempFileName(), reason, false)
+
+	// Decide right away so a joined handle reports the in-flight build
+	prev, err := h.admit(comp)
+	if prev != nil && h.joins(comp, prev) {
+		comp.cancel()
+		return &BuildHandle{comp: prev}
+	}
+
+	go func() {
+		var result *BuildResult
+
+		// Clean up active compilation before the callback so it may start or cancel builds
+		switch {
+		case err != nil:
+			h.settle(comp, nil, err)
+		case prev != nil:
+			// queued behind prev
+			result, err = h.run(comp, h.compileSync)
+		default:
+			result, err = h.compileSync(comp.ctx, comp)
+			h.finish(comp, result, err)
+		}
+
+		if h.config.Callback != nil {
+			h.config.Callback(err)
+		}
+	}()
+
+	return &BuildHandle{comp: comp}
+}
+
+// ID returns the build identifier, the same one reported by History()
+func (b *BuildHandle) ID() uint64 {
+	return b.comp.id
+}
+
+// Done is closed once the build finished, including the temp file cleanup
+func (b *BuildHandle) Done() <-chan struct{} {
+	return b.comp.done
+}
+
+// Wait blocks until the build finished and returns its outcome
+func (b *BuildHandle) Wait() (*BuildResult, error) {
+	<-b.comp.done
+	return b.comp.result, b.comp.err
+}
+
+// Cancel stops this build and waits for the toolchain process to exit
+// It does nothing if the build already finished
+func (b *BuildHandle) Cancel() {
+	b.comp.cancel()
+	<-b.comp.done
+}
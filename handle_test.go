@@ -0,0 +1,118 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStartWait(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, fakeBuildScript)
+
+	callbacks := make(chan error, 1)
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "handleapp",
+		OutFolderRelativePath:     tempDir,
+		Callback:                  func(err error) { callbacks <- err },
+	})
+
+	handle := gb.Start()
+
+	select {
+	case <-handle.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Build did not finish")
+	}
+
+	result, err := handle.Wait()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.OutputPath != gb.FinalOutputPath() {
+		t.Errorf("Expected OutputPath %s, got %s", gb.FinalOutputPath(), result.OutputPath)
+	}
+
+	history := gb.History()
+	if len(history) != 1 || history[0].ID != handle.ID() || history[0].Reason != "Start" {
+		t.Errorf("Expected history entry for handle %d, got %+v", handle.ID(), history)
+	}
+
+	select {
+	case err := <-callbacks:
+		if err != nil {
+			t.Errorf("Expected nil error in callback, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Callback was not called for Start()")
+	}
+}
+
+func TestStartCancelWaitsForCleanup(t *testing.T) {
+	tempDir := t.TempDir()
+	// write the temp output first, then hang like a slow linker
+	tool := writeFakeToolchain(t, tempDir, fakeBuildScript+"\nexec sleep 5")
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "handleapp",
+		OutFolderRelativePath:     tempDir,
+		Timeout:                   10 * time.Second,
+	})
+
+	handle := gb.Start()
+	time.Sleep(200 * time.Millisecond)
+	handle.Cancel()
+
+	select {
+	case <-handle.Done():
+	default:
+		t.Fatal("Cancel returned before the build was done")
+	}
+
+	if _, err := handle.Wait(); !errors.Is(err, ErrCanceled) {
+		t.Errorf("Expected ErrCanceled, got %v", err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(tempDir, "*_temp*"))
+	if len(matches) > 0 {
+		t.Errorf("Temp files should be removed once Cancel returns, found %v", matches)
+	}
+	if _, err := os.Stat(gb.FinalOutputPath()); !os.IsNotExist(err) {
+		t.Errorf("Canceled build must not promote its output")
+	}
+
+	// Cancelling a finished build is a no-op
+	handle.Cancel()
+}
+
+func TestGoBuildCancelWaitsForActiveBuild(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, "exec sleep 5")
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "handleapp",
+		OutFolderRelativePath:     tempDir,
+		Timeout:                   10 * time.Second,
+	})
+
+	handle := gb.Start()
+	time.Sleep(200 * time.Millisecond)
+	gb.Cancel()
+
+	select {
+	case <-handle.Done():
+	default:
+		t.Fatal("GoBuild.Cancel returned before the active build was done")
+	}
+	if gb.IsCompiling() {
+		t.Error("IsCompiling should be false after Cancel")
+	}
+}
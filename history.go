@@ -0,0 +1,102 @@
+package gobuild
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// defaultHistorySize is the number of builds kept when Config.HistorySize is not set
+const defaultHistorySize = 20
+
+// BuildRecord summarizes a finished compilation, see History()
+type BuildRecord struct {
+	ID        uint64 // increases with every compilation of a GoBuild
+	Reason    string // why the build ran, eg: CompileProgram, CompileToMemory
+	StartTime time.Time
+	EndTime   time.Time
+	Success   bool
+	ErrKind   error // one of the Err* kinds, nil on success
+	Err       error
+	Size      int64 // artifact size, 0 on failure
+	Canceled  bool
+	InMemory  bool
+}
+
+// Duration returns how long the build ran
+func (r BuildRecord) Duration() time.Duration {
+	return r.EndTime.Sub(r.StartTime)
+}
+
+// buildHistory is a fixed capacity ring buffer of build records
+type buildHistory struct {
+	mu      sync.Mutex
+	records []BuildRecord
+	next    int  // slot for the next record
+	full    bool // records wrapped around at least once
+}
+
+func newBuildHistory(capacity int) *buildHistory {
+	if capacity <= 0 {
+		capacity = defaultHistorySize
+	}
+	return &buildHistory{records: make([]BuildRecord, capacity)}
+}
+
+func (b *buildHistory) add(r BuildRecord) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.records[b.next] = r
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// list returns the records oldest first
+func (b *buildHistory) list() []BuildRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.full {
+		return append([]BuildRecord(nil), b.records[:b.next]...)
+	}
+
+	out := make([]BuildRecord, 0, len(b.records))
+	out = append(out, b.records[b.next:]...)
+	return append(out, b.records[:b.next]...)
+}
+
+// History returns the last Config.HistorySize builds, oldest first
+func (h *GoBuild) History() []BuildRecord {
+	return h.history.list()
+}
+
+// record adds the outcome of comp to the build history
+func (h *GoBuild) record(comp *compilation, result *BuildResult, err error) {
+	r := BuildRecord{
+		ID:        comp.id,
+		Reason:    comp.reason,
+		StartTime: comp.startTime,
+		EndTime:   time.Now(),
+		Success:   err == nil,
+		Err:       err,
+		InMemory:  comp.inMemory,
+	}
+
+	if result != nil {
+		r.Size = result.Size
+	}
+
+	if err != nil {
+		r.ErrKind = ErrCompile
+		var buildErr *BuildError
+		if errors.As(err, &buildErr) && buildErr.Kind != nil {
+			r.ErrKind = buildErr.Kind
+		}
+		r.Canceled = r.ErrKind == ErrCanceled
+	}
+
+	h.history.add(r)
+}
@@ -0,0 +1,111 @@
+package gobuild
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBuildHistoryRingBuffer(t *testing.T) {
+	b := newBuildHistory(3)
+
+	if got := b.list(); len(got) != 0 {
+		t.Fatalf("Expected empty history, got %d records", len(got))
+	}
+
+	for id := uint64(1); id <= 5; id++ {
+		b.add(BuildRecord{ID: id})
+	}
+
+	got := b.list()
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(got))
+	}
+	for i, want := range []uint64{3, 4, 5} {
+		if got[i].ID != want {
+			t.Errorf("Record %d: expected ID %d, got %d", i, want, got[i].ID)
+		}
+	}
+
+	if defaults := newBuildHistory(0); len(defaults.records) != defaultHistorySize {
+		t.Errorf("Expected default capacity %d, got %d", defaultHistorySize, len(defaults.records))
+	}
+}
+
+func TestHistoryRecordsBuilds(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, fakeBuildScript)
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "historyapp",
+		OutFolderRelativePath:     tempDir,
+		HistorySize:               2,
+		Timeout:                   5 * time.Second,
+	})
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("CompileProgram failed: %v", err)
+	}
+
+	gb.config.Command = "nonexistentcommand"
+	gb.CompileProgram()
+	gb.CompileToMemory()
+
+	history := gb.History()
+	if len(history) != 2 {
+		t.Fatalf("Expected capacity to limit history to 2 records, got %d", len(history))
+	}
+
+	failed, memory := history[0], history[1]
+
+	if failed.ID != 2 || failed.Success || failed.Reason != "CompileProgram" || failed.InMemory {
+		t.Errorf("Unexpected record for failed disk build: %+v", failed)
+	}
+	if !errors.Is(failed.ErrKind, ErrToolchainNotFound) || failed.Err == nil || failed.Canceled {
+		t.Errorf("Expected ErrToolchainNotFound kind, got %+v", failed)
+	}
+	if memory.ID != 3 || memory.Reason != "CompileToMemory" || !memory.InMemory {
+		t.Errorf("Unexpected record for memory build: %+v", memory)
+	}
+	if failed.EndTime.Before(failed.StartTime) || failed.Duration() < 0 {
+		t.Errorf("Expected EndTime after StartTime, got %v - %v", failed.StartTime, failed.EndTime)
+	}
+}
+
+func TestHistoryRecordsSuccessAndCancel(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, fakeBuildScript)
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "historyapp",
+		OutFolderRelativePath:     tempDir,
+		Timeout:                   5 * time.Second,
+	})
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("CompileProgram failed: %v", err)
+	}
+
+	gb.config.Command = writeFakeToolchain(t, t.TempDir(), "exec sleep 5")
+	done := make(chan error, 1)
+	go func() { done <- gb.CompileProgram() }()
+	time.Sleep(200 * time.Millisecond)
+	gb.Cancel()
+	<-done
+
+	history := gb.History()
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(history))
+	}
+
+	if ok := history[0]; !ok.Success || ok.Size != int64(len("fake binary\n")) || ok.ErrKind != nil {
+		t.Errorf("Unexpected record for successful build: %+v", ok)
+	}
+	if canceled := history[1]; !canceled.Canceled || canceled.ErrKind != ErrCanceled {
+		t.Errorf("Expected canceled record, got %+v", canceled)
+	}
+}
@@ -0,0 +1,95 @@
+// Command toolexec wraps the go compile and link steps for gobuild profiling
+// eg: go build -toolexec=/path/to/toolexec ./web
+// Each step is appended as a JSON line to the file named by GOBUILD_PROFILE_FILE
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// profileFileEnv names the file receiving one JSON line per step
+const profileFileEnv = "GOBUILD_PROFILE_FILE"
+
+// step is one tool invocation, mirrored by gobuild.ProfileStep
+type step struct {
+	Tool     string `json:"tool"`    // compile or link
+	Package  string `json:"package"` // import path, the binary for link
+	Nanos    int64  `json:"nanos"`
+	PeakRSS  int64  `json:"rss"` // bytes, 0 when the platform does not report it
+	ExitCode int    `json:"exit"`
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: toolexec tool [args...]")
+		os.Exit(2)
+	}
+	tool, args := os.Args[1], os.Args[2:]
+
+	cmd := exec.Command(tool, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	elapsed := time.Since(start)
+
+	if cmd.ProcessState != nil {
+		record(tool, args, elapsed, cmd.ProcessState)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// record appends the step to the profile file, version queries and other tools are skipped
+func record(tool string, args []string, elapsed time.Duration, state *os.ProcessState) {
+	name := strings.TrimSuffix(filepath.Base(tool), ".exe")
+	if name != "compile" && name != "link" {
+		return
+	}
+
+	s := step{Tool: name, Nanos: elapsed.Nanoseconds(), PeakRSS: peakRSS(state), ExitCode: state.ExitCode()}
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-V") {
+			return
+		}
+		switch {
+		case name == "compile" && arg == "-p" && i+1 < len(args):
+			s.Package = args[i+1]
+		case name == "link" && arg == "-o" && i+1 < len(args):
+			s.Package = filepath.Base(args[i+1])
+		}
+	}
+
+	file := os.Getenv(profileFileEnv)
+	if file == "" {
+		return
+	}
+	line, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+
+	// O_APPEND keeps lines whole while packages compile in parallel
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(line, '\n'))
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// peakRSS is not reported on this platform
+func peakRSS(state *os.ProcessState) int64 {
+	return 0
+}
@@ -0,0 +1,16 @@
+//go:build unix && !darwin
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// peakRSS returns the maximum resident set size of the finished process, Maxrss is in KB
+func peakRSS(state *os.ProcessState) int64 {
+	if ru, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return int64(ru.Maxrss) * 1024
+	}
+	return 0
+}
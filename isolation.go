@@ -0,0 +1,111 @@
+package gobuild
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isolatedDirs maps the toolchain variables redirected by Config.IsolatedDir to their subfolder
+var isolatedDirs = []struct{ env, name string }{
+	{"GOCACHE", "gocache"},
+	{"GOTMPDIR", "tmp"},
+	{"GOPATH", "gopath"},
+}
+
+// buildEnv returns the toolchain environment: the process one, the isolated
+// directories and Config.Env last so it can override them
+func (h *GoBuild) buildEnv() []string {
+	env := os.Environ()
+	if paths := h.isolatedPaths(); paths != nil {
+		for _, d := range isolatedDirs {
+			// created on demand, the toolchain reports the error if this fails
+			os.MkdirAll(paths[d.env], 0755)
+			env = append(env, d.env+"="+paths[d.env])
+		}
+	}
+	return append(env, h.config.Env...)
+}
+
+// envValue returns the last value of key in env like exec.Cmd does, "" when unset
+func envValue(env []string, key string) string {
+	value := ""
+	for _, kv := range env {
+		if v, ok := strings.CutPrefix(kv, key+"="); ok {
+			value = v
+		}
+	}
+	return value
+}
+
+// isolatedPaths returns the absolute isolated directories by variable, eg: GOCACHE: /app/.gobuild/gocache
+// nil when Config.IsolatedDir is not set
+func (h *GoBuild) isolatedPaths() map[string]string {
+	dir := h.config.IsolatedDir
+	if dir == "" {
+		return nil
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(h.config.AppRootDir, dir)
+	}
+	// the toolchain requires absolute paths
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	paths := map[string]string{}
+	for _, d := range isolatedDirs {
+		paths[d.env] = filepath.Join(dir, d.name)
+	}
+	return paths
+}
+
+// ClearIsolatedDirs removes the GOCACHE, GOTMPDIR and GOPATH folders of Config.IsolatedDir
+// The next build starts cold, eg: modules are downloaded again
+func (h *GoBuild) ClearIsolatedDirs() error {
+	for _, dir := range h.isolatedPaths() {
+		// the module cache is read-only
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.IsDir() {
+				os.Chmod(path, 0755)
+			}
+			return nil
+		})
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsolatedDiskUsage returns the bytes used by each isolated folder, eg: GOCACHE: 52428800
+// Missing folders count as 0
+func (h *GoBuild) IsolatedDiskUsage() (map[string]int64, error) {
+	usage := map[string]int64{}
+	for key, dir := range h.isolatedPaths() {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.Type().IsRegular() {
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				usage[key] += info.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := usage[key]; !ok {
+			usage[key] = 0
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,74 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIsolatedDir(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "isolated",
+		Extension:                 getExecutableExtension(),
+		OutFolderRelativePath:     tempDir,
+		IsolatedDir:               ".gobuild",
+		Env:                       []string{"CGO_ENABLED=0"},
+		Timeout:                   2 * time.Minute,
+	})
+
+	result, err := gb.CompileProgramResult()
+	if err != nil {
+		t.Fatalf("Build with IsolatedDir failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"GOCACHE=" + filepath.Join(tempDir, ".gobuild", "gocache"),
+		"GOTMPDIR=" + filepath.Join(tempDir, ".gobuild", "tmp"),
+		"GOPATH=" + filepath.Join(tempDir, ".gobuild", "gopath"),
+	} {
+		if !slices.Contains(result.Env, want) {
+			t.Errorf("Expected %s in the build environment", want)
+		}
+	}
+
+	usage, err := gb.IsolatedDiskUsage()
+	if err != nil {
+		t.Fatalf("IsolatedDiskUsage failed: %v", err)
+	}
+	if usage["GOCACHE"] == 0 {
+		t.Errorf("Expected the build to fill the isolated GOCACHE, got %v", usage)
+	}
+
+	if err := gb.ClearIsolatedDirs(); err != nil {
+		t.Fatalf("ClearIsolatedDirs failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".gobuild", "gocache")); !os.IsNotExist(err) {
+		t.Errorf("Expected the isolated GOCACHE to be removed, got %v", err)
+	}
+	if usage, _ := gb.IsolatedDiskUsage(); usage["GOCACHE"] != 0 {
+		t.Errorf("Expected no usage after clearing, got %v", usage)
+	}
+}
+
+func TestIsolatedDirOverriddenByEnv(t *testing.T) {
+	gb := New(&Config{
+		AppRootDir:  t.TempDir(),
+		IsolatedDir: ".gobuild",
+		Env:         []string{"GOCACHE=/custom/cache"},
+	})
+
+	env := gb.buildEnv()
+	if env[len(env)-1] != "GOCACHE=/custom/cache" {
+		t.Errorf("Expected Config.Env to come last and win, got %v", env[len(env)-4:])
+	}
+}
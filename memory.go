@@ -3,8 +3,6 @@ package gobuild
 import (
 	"bytes"
 	"context"
-	"fmt"
-	"os"
 	"os/exec"
 	"time"
 )
@@ -12,28 +10,34 @@ import (
 // CompileToMemory compiles the Go program returning the binary as a byte slice.
 // It avoids writing to physical disk by using stdout.
 func (h *GoBuild) CompileToMemory() ([]byte, error) {
-	h.mu.Lock()
+	return h.CompileToMemoryContext(context.Background())
+}
 
-	// Cancel any active compilation
-	if h.active != nil {
-		h.active.cancel()
-		h.active = nil
+// CompileToMemoryContext is CompileToMemory bound to ctx, eg: a request or shutdown context
+// The build stops when ctx is done or Config.Timeout expires, whichever comes first
+func (h *GoBuild) CompileToMemoryContext(ctx context.Context) ([]byte, error) {
+	result, err := h.compileToMemoryResult(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return result.Bytes, nil
+}
 
-	// Create new compilation context
-	ctx, cancel := context.WithTimeout(context.Background(), h.config.Timeout)
+// CompileToMemoryResult is CompileToMemory reporting the build details
+// The binary is available in BuildResult.Bytes
+func (h *GoBuild) CompileToMemoryResult() (*BuildResult, error) {
+	return h.compileToMemoryResult(context.Background())
+}
 
+func (h *GoBuild) compileToMemoryResult(parent context.Context) (*BuildResult, error) {
 	// In-memory compilation doesn't use temp files on disk, but we need a "compilation" struct
 	// to track state/cancellation.
-	comp := &compilation{
-		cancel:    cancel,
-		done:      make(chan error, 1),
-		tempFile:  "memory", // Virtual placeholder
-		startTime: time.Now(),
-	}
+	comp := h.newCompilation(parent, "memory", "CompileToMemory", true) // Virtual placeholder
+	return h.run(comp, h.compileMemory)
+}
 
-	h.active = comp
-	h.mu.Unlock()
+// compileMemory runs the toolchain writing the binary to stdout
+func (h *GoBuild) compileMemory(ctx context.Context, comp *compilation) (*BuildResult, error) {
 
 	// Build arguments: -o /dev/stdout ...
 	// Note: We use h.buildArguments but we need to override the output file.
@@ -48,56 +52,92 @@ func (h *GoBuild) CompileToMemory() ([]byte, error) {
 
 	// Construct arguments using the shared logic which handles ldflags, input paths, etc.
 	// Because outputDest starts with /dev/, buildArguments will treat it as absolute/special.
+	if err := h.checkArguments(); err != nil {
+		return nil, err
+	}
+	if err := h.ensureProfiler(ctx); err != nil {
+		return nil, err
+	}
 	args := h.buildArguments(outputDest)
 
 	cmd := exec.CommandContext(ctx, h.config.Command, args...)
 	cmd.Dir = h.config.AppRootDir
+	h.configureProcess(cmd)
+	comp.cmd = cmd
 
-	// Environment variables - inherit current env and add config overrides
-	cmd.Env = os.Environ()
-	// Add/Override env vars from config (e.g., GOOS=js, GOARCH=wasm for WASM builds)
-	if len(h.config.Env) > 0 {
-		cmd.Env = append(cmd.Env, h.config.Env...)
-	}
+	// Environment variables - inherit current env, isolated dirs and config overrides
+	// (e.g., GOOS=js, GOARCH=wasm for WASM builds)
+	cmd.Env = h.buildEnv()
 
 	// Capture Stdout
 	var wasmBuffer bytes.Buffer
 	cmd.Stdout = &wasmBuffer
 
-	// Capture Stderr for logs (and pass to logger if needed)
-	// We can use a buffer for stderr too to log on error
-	var stderrBuffer bytes.Buffer
-	cmd.Stderr = &stderrBuffer
+	// Stderr is streamed live (when configured) and kept for the returned error
+	stderr := h.newOutputWriter()
+	cmd.Stderr = stderr
 
-	err := cmd.Run()
+	result := newBuildResult(comp)
 
-	if ctx.Err() == context.DeadlineExceeded {
-		// Clean up active state
-		h.mu.Lock()
-		if h.active == comp {
-			h.active = nil
-		}
-		h.mu.Unlock()
-		return nil, fmt.Errorf("compilation timed out after %v", h.config.Timeout)
+	cache := h.cacheEntry(args)
+	if data, ok := cache.lookup(); ok {
+		result.CacheHit = true
+		return h.storeMemory(ctx, result, data)
 	}
+	if err := cache.snapshot(ctx, h); err != nil {
+		h.logCacheError(err)
+	}
+	profile := h.startProfile(cmd)
+
+	err := cmd.Run()
+	reapProcessGroup(cmd)
+	stderr.Flush()
+	result.Profile = profile.finish()
 
 	if err != nil {
-		// Clean up active state
-		h.mu.Lock()
-		if h.active == comp {
-			h.active = nil
-		}
-		h.mu.Unlock()
-		return nil, fmt.Errorf("compilation failed: %w\nOutput: %s", err, stderrBuffer.String())
+		return nil, h.newBuildError(ctx, err, stderr.Bytes())
 	}
 
-	// Store compiled bytes in active compilation for BinarySize() access
+	result.setOutput(h, stderr.Bytes())
+
 	compiledBytes := wasmBuffer.Bytes()
-	h.mu.Lock()
-	if h.active == comp {
-		h.active.memoryBytes = compiledBytes
+	result, err = h.storeMemory(ctx, result, compiledBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// only accepted artifacts are cached
+	if err := cache.store(compiledBytes); err != nil {
+		h.logCacheError(err)
+	}
+	return result, nil
+}
+
+// storeMemory post-processes data and records it as the in-memory artifact of result
+func (h *GoBuild) storeMemory(ctx context.Context, result *BuildResult, data []byte) (*BuildResult, error) {
+	data, err := h.postProcess(ctx, result, data)
+	if err != nil {
+		return nil, err
 	}
+	if err := h.validateArtifact(data); err != nil {
+		return nil, err
+	}
+
+	compressed, err := h.compress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Bytes = data
+	result.Compressed = compressed
+	result.setArtifact(data)
+
+	// Store compiled bytes for BinarySize() access
+	h.mu.Lock()
+	h.memoryBytes = data
+	h.memoryEncoded = compressed
 	h.mu.Unlock()
 
-	return compiledBytes, nil
+	result.Duration = time.Since(result.StartTime)
+	return result, nil
 }
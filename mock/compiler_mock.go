@@ -6,6 +6,9 @@ type FakeCompiler struct {
 	CompileCallCount int
 	Output           string
 	Unobserved       []string
+	Inputs           []string
+	InputsErr        error
+	Recompile        func(path string) bool // nil answers true
 }
 
 // CompileProgram mocks the CompileProgram method.
@@ -23,3 +26,16 @@ func (f *FakeCompiler) FinalOutputPath() string {
 func (f *FakeCompiler) UnobservedFiles() []string {
 	return f.Unobserved
 }
+
+// InputFiles mocks the InputFiles method.
+func (f *FakeCompiler) InputFiles() ([]string, error) {
+	return f.Inputs, f.InputsErr
+}
+
+// ShouldRecompile mocks the ShouldRecompile method.
+func (f *FakeCompiler) ShouldRecompile(path string) bool {
+	if f.Recompile == nil {
+		return true
+	}
+	return f.Recompile(path)
+}
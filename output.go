@@ -0,0 +1,74 @@
+package gobuild
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// outputWriter collects the toolchain output while forwarding it live
+// to Config.OutputWriter and, line by line, to Config.Logger
+type outputWriter struct {
+	mu      sync.Mutex
+	all     bytes.Buffer // full output, kept for the returned error
+	line    []byte       // pending partial line
+	logger  func(message ...any)
+	forward io.Writer
+}
+
+// newOutputWriter returns a writer honoring Config.StreamOutput and Config.OutputWriter
+func (h *GoBuild) newOutputWriter() *outputWriter {
+	w := &outputWriter{forward: h.config.OutputWriter}
+	if h.config.StreamOutput {
+		w.logger = h.config.Logger
+	}
+	return w
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.all.Write(p)
+
+	if w.forward != nil {
+		// a failing consumer must not break the build
+		w.forward.Write(p)
+	}
+
+	if w.logger != nil {
+		w.line = append(w.line, p...)
+		for {
+			i := bytes.IndexByte(w.line, '\n')
+			if i < 0 {
+				break
+			}
+			w.logLine(w.line[:i])
+			w.line = w.line[i+1:]
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush sends the last unterminated line to the Logger
+func (w *outputWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.line) > 0 {
+		w.logLine(w.line)
+		w.line = nil
+	}
+}
+
+// Bytes returns everything written so far
+func (w *outputWriter) Bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.all.Bytes()
+}
+
+func (w *outputWriter) logLine(line []byte) {
+	w.logger(string(bytes.TrimRight(line, "\r")))
+}
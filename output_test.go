@@ -0,0 +1,84 @@
+package gobuild
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestOutputWriterSplitsLines(t *testing.T) {
+	var lines []string
+	var forwarded bytes.Buffer
+
+	w := &outputWriter{
+		logger:  func(message ...any) { lines = append(lines, message[0].(string)) },
+		forward: &forwarded,
+	}
+
+	w.Write([]byte("# command-line-arguments\n./main.go:3"))
+	w.Write([]byte(":2: undefined: x\r\n"))
+	w.Write([]byte("last line without newline"))
+
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 complete lines before Flush, got %q", lines)
+	}
+
+	w.Flush()
+
+	expected := []string{"# command-line-arguments", "./main.go:3:2: undefined: x", "last line without newline"}
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected lines %q, got %q", expected, lines)
+	}
+
+	all := "# command-line-arguments\n./main.go:3:2: undefined: x\r\nlast line without newline"
+	if string(w.Bytes()) != all {
+		t.Errorf("Expected full output to be kept, got %q", w.Bytes())
+	}
+	if forwarded.String() != all {
+		t.Errorf("Expected raw output to be forwarded, got %q", forwarded.String())
+	}
+}
+
+func TestStreamOutputDuringBuild(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, `echo "compiling pkg"; echo "main.go:1:1: broken" >&2; exit 1`)
+
+	var mu sync.Mutex
+	var logged []string
+	var forwarded bytes.Buffer
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "test",
+		OutFolderRelativePath:     tempDir,
+		StreamOutput:              true,
+		OutputWriter:              &forwarded,
+		Logger: func(message ...any) {
+			mu.Lock()
+			defer mu.Unlock()
+			logged = append(logged, message[0].(string))
+		},
+	})
+
+	err := gb.CompileProgram()
+
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("Expected *BuildError, got %v", err)
+	}
+	if !strings.Contains(buildErr.Output, "compiling pkg") || !strings.Contains(buildErr.Output, "main.go:1:1: broken") {
+		t.Errorf("Expected full output in error, got %q", buildErr.Output)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(logged, "|") != "compiling pkg|main.go:1:1: broken" {
+		t.Errorf("Expected each line to reach the Logger, got %q", logged)
+	}
+	if forwarded.String() != buildErr.Output {
+		t.Errorf("Expected OutputWriter to receive the raw output, got %q", forwarded.String())
+	}
+}
@@ -0,0 +1,68 @@
+package gobuild
+
+import (
+	"context"
+	"time"
+)
+
+// ConcurrencyPolicy decides what a new compilation does while another one is active
+type ConcurrencyPolicy int
+
+const (
+	CancelPrevious ConcurrencyPolicy = iota // cancel the active compilation and start (default)
+	Queue                                   // wait for the active compilation to finish, then start
+	DropIfBusy                              // return ErrBusy without compiling
+	JoinInFlight                            // return the outcome of the active compilation of the same kind (disk or memory)
+)
+
+// admit makes comp the active compilation according to Config.ConcurrencyPolicy
+// It returns the active compilation comp has to wait for or join instead,
+// or ErrBusy when DropIfBusy refuses comp
+func (h *GoBuild) admit(comp *compilation) (*compilation, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if prev := h.active; prev != nil {
+		switch h.config.ConcurrencyPolicy {
+		case Queue, JoinInFlight:
+			return prev, nil
+		case DropIfBusy:
+			return nil, &BuildError{Kind: ErrBusy}
+		default:
+			// Cancel any active compilation
+			prev.cancel()
+			// Don't wait for it to finish, just move on
+		}
+	}
+
+	comp.ctx, comp.stop = context.WithTimeoutCause(comp.ctx, h.config.Timeout, ErrTimeout)
+	comp.startTime = time.Now()
+	h.active = comp
+	return nil, nil
+}
+
+// acquire waits until comp is admitted
+// It returns the in-flight compilation to share when JoinInFlight applies
+func (h *GoBuild) acquire(comp *compilation) (*compilation, error) {
+	for {
+		prev, err := h.admit(comp)
+		if err != nil || prev == nil {
+			return nil, err
+		}
+
+		if h.joins(comp, prev) {
+			return prev, nil
+		}
+
+		select {
+		case <-prev.done:
+		case <-comp.ctx.Done():
+			return nil, h.newBuildError(comp.ctx, comp.ctx.Err(), nil)
+		}
+	}
+}
+
+// joins reports whether comp shares the outcome of the in-flight prev
+func (h *GoBuild) joins(comp, prev *compilation) bool {
+	return h.config.ConcurrencyPolicy == JoinInFlight && comp.inMemory == prev.inMemory
+}
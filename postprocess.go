@@ -0,0 +1,88 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PostProcessor rewrites the artifact between the build and its promotion
+// eg: run wasm-opt, strip custom sections, patch metadata
+type PostProcessor interface {
+	Name() string // used in BuildResult.Processed and errors, eg: wasm-opt
+	Process(ctx context.Context, data []byte) ([]byte, error)
+}
+
+// NewPostProcessor returns a PostProcessor running fn
+// eg: gobuild.NewPostProcessor("strip-names", stripNameSection)
+func NewPostProcessor(name string, fn func(ctx context.Context, data []byte) ([]byte, error)) PostProcessor {
+	return &funcPostProcessor{name: name, fn: fn}
+}
+
+type funcPostProcessor struct {
+	name string
+	fn   func(ctx context.Context, data []byte) ([]byte, error)
+}
+
+func (p *funcPostProcessor) Name() string { return p.name }
+
+func (p *funcPostProcessor) Process(ctx context.Context, data []byte) ([]byte, error) {
+	return p.fn(ctx, data)
+}
+
+// PostProcessStep reports one PostProcessor run
+type PostProcessStep struct {
+	Name       string
+	Duration   time.Duration
+	SizeBefore int64
+	SizeAfter  int64
+}
+
+// PostProcessError attributes a failure to the PostProcessor that caused it
+// It is the Err of a *BuildError whose Kind is ErrPostProcess
+type PostProcessError struct {
+	Step  string // PostProcessor name
+	Index int    // position in Config.PostProcessors
+	Err   error
+}
+
+func (e *PostProcessError) Error() string {
+	return fmt.Sprintf("post-processor %s (#%d): %v", e.Step, e.Index, e.Err)
+}
+
+func (e *PostProcessError) Unwrap() error {
+	return e.Err
+}
+
+// postProcess runs Config.PostProcessors in order on data and records each step in result
+// On failure nothing is promoted, the caller removes the temp file
+func (h *GoBuild) postProcess(ctx context.Context, result *BuildResult, data []byte) ([]byte, error) {
+	for i, p := range h.config.PostProcessors {
+		start := time.Now()
+		out, err := p.Process(ctx, data)
+		if err == nil && len(out) == 0 {
+			err = errors.New("returned an empty artifact")
+		}
+		if err != nil {
+			kind := stopKind(ctx)
+			if kind == nil {
+				kind = ErrPostProcess
+			}
+			return nil, &BuildError{
+				Kind:   kind,
+				Err:    &PostProcessError{Step: p.Name(), Index: i, Err: err},
+				Output: result.Output,
+			}
+		}
+
+		result.Processed = append(result.Processed, PostProcessStep{
+			Name:       p.Name(),
+			Duration:   time.Since(start),
+			SizeBefore: int64(len(data)),
+			SizeAfter:  int64(len(out)),
+		})
+		data = out
+	}
+	return data, nil
+}
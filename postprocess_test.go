@@ -0,0 +1,100 @@
+package gobuild
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	upperProcessor = NewPostProcessor("upper", func(ctx context.Context, data []byte) ([]byte, error) {
+		return bytes.ToUpper(data), nil
+	})
+	stampProcessor = NewPostProcessor("stamp", func(ctx context.Context, data []byte) ([]byte, error) {
+		return append(data, "stamped\n"...), nil
+	})
+	failingProcessor = NewPostProcessor("wasm-opt", func(ctx context.Context, data []byte) ([]byte, error) {
+		return nil, errors.New("exit status 1")
+	})
+)
+
+func TestPostProcessors(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, fakeBuildScript)
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "processed",
+		OutFolderRelativePath:     tempDir,
+		PostProcessors:            []PostProcessor{upperProcessor, stampProcessor},
+	})
+
+	result, err := gb.CompileProgramResult()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	const want = "FAKE BINARY\nstamped\n"
+	if data, _ := os.ReadFile(gb.FinalOutputPath()); string(data) != want {
+		t.Errorf("Expected the processed artifact on disk, got %q", data)
+	}
+	if result.Size != int64(len(want)) {
+		t.Errorf("Expected Size of the processed artifact, got %d", result.Size)
+	}
+
+	if len(result.Processed) != 2 || result.Processed[0].Name != "upper" || result.Processed[1].Name != "stamp" {
+		t.Fatalf("Expected one step per processor in order, got %+v", result.Processed)
+	}
+	if s := result.Processed[1]; s.SizeBefore != 12 || s.SizeAfter != int64(len(want)) {
+		t.Errorf("Expected sizes before/after the step, got %+v", s)
+	}
+
+	memory, err := gb.CompileToMemoryResult()
+	if err != nil {
+		t.Fatalf("Memory build failed: %v", err)
+	}
+	if string(memory.Bytes) != want || len(memory.Processed) != 2 {
+		t.Errorf("Expected processed bytes in memory, got %q %+v", memory.Bytes, memory.Processed)
+	}
+}
+
+func TestPostProcessorFailure(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, fakeBuildScript)
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "processed",
+		OutFolderRelativePath:     tempDir,
+		PostProcessors:            []PostProcessor{upperProcessor, failingProcessor},
+	})
+
+	if err := os.WriteFile(gb.FinalOutputPath(), []byte("previous build"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := gb.CompileProgram()
+	if !errors.Is(err, ErrPostProcess) {
+		t.Fatalf("Expected ErrPostProcess, got %v", err)
+	}
+	var stepErr *PostProcessError
+	if !errors.As(err, &stepErr) || stepErr.Step != "wasm-opt" || stepErr.Index != 1 {
+		t.Errorf("Expected the failure attributed to wasm-opt (#1), got %v", err)
+	}
+
+	if data, _ := os.ReadFile(gb.FinalOutputPath()); string(data) != "previous build" {
+		t.Errorf("Expected the final file to stay untouched, got %q", data)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(tempDir, "processed_temp*")); len(matches) != 0 {
+		t.Errorf("Expected the temp file to be removed, found %v", matches)
+	}
+
+	if _, err := gb.CompileToMemory(); !errors.Is(err, ErrPostProcess) {
+		t.Errorf("CompileToMemory: expected ErrPostProcess, got %v", err)
+	}
+}
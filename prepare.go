@@ -0,0 +1,101 @@
+package gobuild
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"time"
+)
+
+// Prepare warms the caches so the first build fits in Config.Timeout
+// It downloads the modules and builds every dependency of the main package for
+// the configured Env and flags, eg: the standard library for GOOS=js GOARCH=wasm
+// Progress is reported through Logger, the output folder is not touched
+// Config.Timeout does not apply, ctx bounds the whole warm-up
+// Config.Command runs every step, eg: go1.22.5 warms its own GOCACHE
+func (h *GoBuild) Prepare(ctx context.Context) error {
+	start := time.Now()
+
+	if findGoMod(h.config.AppRootDir) != "" {
+		h.logPrepare("downloading modules")
+		// tinygo has no mod command, it resolves modules through the go on PATH
+		tool := h.config.Command
+		if h.isTinyGo() {
+			tool = "go"
+		}
+		if err := h.runTool(ctx, tool, "mod", "download"); err != nil {
+			return err
+		}
+	}
+
+	h.logPrepare("listing dependencies")
+	pkgs, err := h.listPackages(ctx)
+	if err != nil {
+		return err
+	}
+
+	var deps []string
+	for _, p := range pkgs {
+		if p.Name != "main" && p.ImportPath != "command-line-arguments" {
+			deps = append(deps, p.ImportPath)
+		}
+	}
+
+	// tinygo keeps its own cache and has no dependency-only build
+	if h.isTinyGo() || len(deps) == 0 {
+		h.logPrepare(fmt.Sprintf("done in %v", time.Since(start).Round(time.Millisecond)))
+		return nil
+	}
+
+	h.logPrepare(fmt.Sprintf("building %d dependencies", len(deps)))
+	// building several packages discards the results, only the build cache is filled
+	args := append([]string{"build"}, h.prepareFlags()...)
+	if err := h.runTool(ctx, h.config.Command, append(args, deps...)...); err != nil {
+		return err
+	}
+
+	h.logPrepare(fmt.Sprintf("done in %v", time.Since(start).Round(time.Millisecond)))
+	return nil
+}
+
+// prepareFlags returns the flags of CompilingArguments that change the compiled packages
+// Link flags are left out, eg: -ldflags only affects the final binary
+func (h *GoBuild) prepareFlags() []string {
+	flags := h.pickFlags("-tags", "-mod", "-modfile", "-pgo", "-gcflags", "-asmflags")
+	if h.config.CompilingArguments != nil {
+		for _, arg := range h.config.CompilingArguments() {
+			if slices.Contains([]string{"-trimpath", "-race", "-msan", "-asan"}, arg) {
+				flags = append(flags, arg)
+			}
+		}
+	}
+	return flags
+}
+
+// runTool runs a toolchain command in AppRootDir with the build environment
+func (h *GoBuild) runTool(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = h.config.AppRootDir
+	cmd.Env = h.buildEnv()
+	h.configureProcess(cmd)
+
+	out := h.newOutputWriter()
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	err := cmd.Run()
+	reapProcessGroup(cmd)
+	out.Flush()
+
+	if err != nil {
+		return h.newBuildError(ctx, err, out.Bytes())
+	}
+	return nil
+}
+
+func (h *GoBuild) logPrepare(step string) {
+	if h.config.Logger != nil {
+		h.config.Logger("Prepare:", step)
+	}
+}
@@ -0,0 +1,112 @@
+package gobuild
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPrepare(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"go.mod":      "module example.com/app\n\ngo 1.22\n",
+		"web/main.go": "package main\n\nimport _ \"example.com/app/ui\"\n\nfunc main() {}\n",
+		"ui/ui.go":    "package ui\n",
+	})
+	outDir := filepath.Join(tempDir, "public")
+	if err := os.Mkdir(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var logged []string
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "web/main.go",
+		OutName:                   "main",
+		OutFolderRelativePath:     outDir,
+		IsolatedDir:               ".gobuild",
+		Env:                       []string{"CGO_ENABLED=0"},
+		Logger: func(message ...any) {
+			mu.Lock()
+			defer mu.Unlock()
+			logged = append(logged, fmt.Sprintln(message...))
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if err := gb.Prepare(ctx); err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	mu.Lock()
+	progress := strings.Join(logged, "")
+	mu.Unlock()
+	for _, step := range []string{"downloading modules", "building", "done in"} {
+		if !strings.Contains(progress, step) {
+			t.Errorf("Expected progress %q in logs:\n%s", step, progress)
+		}
+	}
+
+	if entries, _ := os.ReadDir(outDir); len(entries) != 0 {
+		t.Errorf("Prepare must not touch the output folder, found %v", entries)
+	}
+	if usage, _ := gb.IsolatedDiskUsage(); usage["GOCACHE"] == 0 {
+		t.Error("Expected Prepare to warm the build cache")
+	}
+}
+
+func TestPrepareCanceled(t *testing.T) {
+	tempDir := t.TempDir()
+	writeMainGo(t, tempDir, "never built")
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := gb.Prepare(ctx); err == nil {
+		t.Error("Expected an error for a canceled context")
+	}
+}
+
+func TestPrepareUsesConfiguredCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"go.mod":  "module example.com/app\n\ngo 1.22\n",
+		"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println() }\n",
+	})
+	calls := filepath.Join(tempDir, "calls.log")
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   writeVersionedToolchain(t, tempDir, calls),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Env:                       []string{"CGO_ENABLED=0"},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if err := gb.Prepare(ctx); err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(calls)
+	for _, step := range []string{"mod download", "list ", "build "} {
+		if !strings.Contains(string(data), step) {
+			t.Errorf("Expected %q to run through Config.Command, got calls:\n%s", step, data)
+		}
+	}
+}
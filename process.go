@@ -0,0 +1,13 @@
+package gobuild
+
+import "time"
+
+// defaultKillGracePeriod is used when Config.KillGracePeriod is not set
+const defaultKillGracePeriod = 2 * time.Second
+
+func (h *GoBuild) killGracePeriod() time.Duration {
+	if h.config.KillGracePeriod > 0 {
+		return h.config.KillGracePeriod
+	}
+	return defaultKillGracePeriod
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package gobuild
+
+import "os/exec"
+
+// configureProcess bounds how long Wait blocks once the toolchain is canceled
+// Process groups are not available, only the driver process is killed
+func (h *GoBuild) configureProcess(cmd *exec.Cmd) {
+	cmd.WaitDelay = h.killGracePeriod()
+}
+
+// reapProcessGroup is a no-op without process groups
+func reapProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package gobuild
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureProcess runs the toolchain in its own process group so cancel and timeout
+// reach compile, link and tinygo's LLVM subprocesses, not only the driver
+// The group gets SIGTERM first, the driver is killed after Config.KillGracePeriod
+func (h *GoBuild) configureProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = h.killGracePeriod()
+}
+
+// reapProcessGroup kills whatever is left of the toolchain process group
+// It must run after cmd.Wait and before the temp file is removed
+func reapProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	// ESRCH is expected when every process already exited
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
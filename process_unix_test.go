@@ -0,0 +1,70 @@
+//go:build unix
+
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCancelKillsProcessGroup(t *testing.T) {
+	tempDir := t.TempDir()
+	marker := filepath.Join(tempDir, "child-survived")
+
+	// a background subprocess like compile/link, outliving the driver unless its group is killed
+	tool := writeFakeToolchain(t, tempDir, "(sleep 0.5; echo alive > "+marker+") &\nexec sleep 5")
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "groupapp",
+		OutFolderRelativePath:     tempDir,
+		Timeout:                   10 * time.Second,
+	})
+
+	handle := gb.Start()
+	time.Sleep(200 * time.Millisecond)
+	handle.Cancel()
+
+	if _, err := handle.Wait(); !errors.Is(err, ErrCanceled) {
+		t.Errorf("Expected ErrCanceled, got %v", err)
+	}
+
+	time.Sleep(700 * time.Millisecond)
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("Toolchain subprocess kept running after Cancel")
+	}
+}
+
+func TestTimeoutEscalatesToKill(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// ignores SIGTERM, only SIGKILL after the grace period stops it
+	tool := writeFakeToolchain(t, tempDir, "trap '' TERM\nsleep 5\nsleep 5")
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "groupapp",
+		OutFolderRelativePath:     tempDir,
+		Timeout:                   100 * time.Millisecond,
+		KillGracePeriod:           200 * time.Millisecond,
+	})
+
+	start := time.Now()
+	err := gb.CompileProgram()
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+	if elapsed > 3*time.Second {
+		t.Errorf("Expected SIGKILL after the grace period, build took %v", elapsed)
+	}
+	if elapsed < 300*time.Millisecond {
+		t.Errorf("Expected the grace period to be honored, build took %v", elapsed)
+	}
+}
@@ -0,0 +1,223 @@
+package gobuild
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// toolexecSource is the -toolexec helper, built once per go toolchain
+//
+//go:embed internal/toolexec/*.go
+var toolexecSource embed.FS
+
+// profileFileEnv must match internal/toolexec
+const profileFileEnv = "GOBUILD_PROFILE_FILE"
+
+// ProfileStep is one compile or link run recorded by Config.Profile
+type ProfileStep struct {
+	Tool     string // compile or link
+	Package  string // import path, the output file for link, eg: main_temp_1700000000.wasm
+	Duration time.Duration
+	PeakRSS  int64 // bytes, 0 when the platform does not report it
+	Failed   bool
+}
+
+// BuildProfile lists the steps of one build, slowest first
+// Packages restored from the go build cache are not compiled and do not appear
+type BuildProfile struct {
+	Steps []ProfileStep
+}
+
+// String renders the profile as a table, eg:
+//
+//	STEP     PACKAGE   TIME   PEAK RSS
+//	link     main.wasm 1.2s   210.5 MB
+func (p *BuildProfile) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STEP\tPACKAGE\tTIME\tPEAK RSS")
+	for _, s := range p.Steps {
+		rss := "-"
+		if s.PeakRSS > 0 {
+			rss = formatSize(s.PeakRSS)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%v\t%s\n", s.Tool, s.Package, s.Duration.Round(time.Millisecond), rss)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// profileRun collects the steps of one profiled build
+type profileRun struct {
+	file string
+}
+
+// startProfile points cmd to a fresh profile file, nil when profiling is off
+func (h *GoBuild) startProfile(cmd *exec.Cmd) *profileRun {
+	if !h.profiling() {
+		return nil
+	}
+	f, err := os.CreateTemp("", "gobuild-profile-*.jsonl")
+	if err != nil {
+		return nil
+	}
+	f.Close()
+
+	cmd.Env = append(cmd.Env, profileFileEnv+"="+f.Name())
+	return &profileRun{file: f.Name()}
+}
+
+// finish reads the recorded steps, sorts them slowest first and removes the file
+func (p *profileRun) finish() *BuildProfile {
+	if p == nil {
+		return nil
+	}
+	defer os.Remove(p.file)
+
+	f, err := os.Open(p.file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	profile := &BuildProfile{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line struct {
+			Tool    string `json:"tool"`
+			Package string `json:"package"`
+			Nanos   int64  `json:"nanos"`
+			PeakRSS int64  `json:"rss"`
+			Exit    int    `json:"exit"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &line) != nil {
+			continue
+		}
+		profile.Steps = append(profile.Steps, ProfileStep{
+			Tool:     line.Tool,
+			Package:  line.Package,
+			Duration: time.Duration(line.Nanos),
+			PeakRSS:  line.PeakRSS,
+			Failed:   line.Exit != 0,
+		})
+	}
+
+	sort.SliceStable(profile.Steps, func(i, j int) bool {
+		return profile.Steps[i].Duration > profile.Steps[j].Duration
+	})
+	return profile
+}
+
+// profiling reports whether this build runs through the -toolexec helper
+// tinygo has no -toolexec, Config.Profile is ignored there
+func (h *GoBuild) profiling() bool {
+	return h.config.Profile && !h.isTinyGo()
+}
+
+// ensureProfiler builds the -toolexec helper on first use
+func (h *GoBuild) ensureProfiler(ctx context.Context) error {
+	if !h.profiling() {
+		return nil
+	}
+
+	h.profilerMu.Lock()
+	defer h.profilerMu.Unlock()
+	if h.profilerPath != "" {
+		return nil
+	}
+
+	helper, err := buildToolexecHelper(ctx)
+	if err != nil {
+		return &BuildError{Kind: errorKind(ctx, err), Err: fmt.Errorf("building -toolexec helper: %w", err)}
+	}
+	h.profilerPath = helper
+	return nil
+}
+
+// toolexecArgs returns the -toolexec flag when profiling
+func (h *GoBuild) toolexecArgs() []string {
+	if !h.profiling() {
+		return nil
+	}
+	h.profilerMu.Lock()
+	defer h.profilerMu.Unlock()
+	if h.profilerPath == "" {
+		return nil
+	}
+	return []string{"-toolexec=" + h.profilerPath}
+}
+
+// buildToolexecHelper compiles the embedded helper for the host into the user cache dir
+// The binary is reused while the sources and the go toolchain stay the same
+func buildToolexecHelper(ctx context.Context) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintln(hash, toolchainID("go"), runtime.GOOS, runtime.GOARCH)
+	files, _ := fs.Glob(toolexecSource, "internal/toolexec/*.go")
+	for _, name := range files {
+		data, _ := toolexecSource.ReadFile(name)
+		fmt.Fprintln(hash, name)
+		hash.Write(data)
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	name := "toolexec"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	helper := filepath.Join(cacheDir, "gobuild", "toolexec-"+hex.EncodeToString(hash.Sum(nil))[:16], name)
+	if fileExists(helper) {
+		return helper, nil
+	}
+
+	src, err := os.MkdirTemp("", "gobuild-toolexec-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(src)
+
+	for _, name := range files {
+		data, _ := toolexecSource.ReadFile(name)
+		if err := os.WriteFile(filepath.Join(src, filepath.Base(name)), data, 0644); err != nil {
+			return "", err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(src, "go.mod"), []byte("module toolexec\n\ngo 1.22\n"), 0644); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(helper), 0755); err != nil {
+		return "", err
+	}
+
+	// built for the host whatever the target of the profiled build is
+	tmp := helper + fmt.Sprintf(".tmp%d", time.Now().UnixNano())
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", tmp, ".")
+	cmd.Dir = src
+	cmd.Env = append(os.Environ(), "GOOS=", "GOARCH=", "GOFLAGS=", "GOWORK=off", "CGO_ENABLED=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(out)))
+	}
+	if err := os.Rename(tmp, helper); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return helper, nil
+}
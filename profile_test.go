@@ -0,0 +1,74 @@
+package gobuild
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfileBuild(t *testing.T) {
+	tempDir := t.TempDir()
+	writeMainGo(t, tempDir, "Hello from profile test!")
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "profiled",
+		Extension:                 getExecutableExtension(),
+		OutFolderRelativePath:     tempDir,
+		Env:                       []string{"CGO_ENABLED=0"},
+		Profile:                   true,
+		Timeout:                   2 * time.Minute,
+	})
+
+	result, err := gb.CompileProgramResult()
+	if err != nil {
+		t.Fatalf("Profiled build failed: %v", err)
+	}
+	if result.Profile == nil {
+		t.Fatal("Expected a profile")
+	}
+	if !strings.Contains(strings.Join(result.Args, " "), "-toolexec=") {
+		t.Errorf("Expected -toolexec in the build arguments, got %v", result.Args)
+	}
+
+	// main is never cached by the go build cache, compile and link always run
+	var compiled, linked bool
+	for i, s := range result.Profile.Steps {
+		if i > 0 && s.Duration > result.Profile.Steps[i-1].Duration {
+			t.Errorf("Expected steps sorted slowest first, got %v", result.Profile.Steps)
+		}
+		compiled = compiled || (s.Tool == "compile" && s.Package == "main")
+		linked = linked || s.Tool == "link"
+	}
+	if !compiled || !linked {
+		t.Errorf("Expected compile of main and link steps, got %+v", result.Profile.Steps)
+	}
+
+	if table := result.Profile.String(); !strings.HasPrefix(table, "STEP") || !strings.Contains(table, "link") {
+		t.Errorf("Unexpected profile table:\n%s", table)
+	}
+	if _, err := os.Stat(gb.FinalOutputPath()); err != nil {
+		t.Errorf("Expected the profiled build to be promoted: %v", err)
+	}
+}
+
+func TestBuildProfileString(t *testing.T) {
+	p := &BuildProfile{Steps: []ProfileStep{
+		{Tool: "link", Package: "main.wasm", Duration: 1200 * time.Millisecond, PeakRSS: 3 << 20},
+		{Tool: "compile", Package: "fmt", Duration: 300 * time.Millisecond},
+	}}
+
+	lines := strings.Split(strings.TrimSpace(p.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %q", lines)
+	}
+	if !strings.Contains(lines[1], "1.2s") || !strings.Contains(lines[1], "3.0 MB") {
+		t.Errorf("Unexpected link row %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], "-") {
+		t.Errorf("Expected '-' for unknown peak RSS, got %q", lines[2])
+	}
+}
@@ -1,6 +1,7 @@
 package gobuild
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"sync"
@@ -312,3 +313,149 @@ func main() {
 
 	t.Logf("Async race test completed: %d successful compilations", successCount)
 }
+
+// newPolicyCompiler returns a GoBuild whose fake toolchain needs 300ms per build
+func newPolicyCompiler(t *testing.T, policy ConcurrencyPolicy) *GoBuild {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, "sleep 0.3\n"+fakeBuildScript)
+
+	return New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "policyapp",
+		OutFolderRelativePath:     tempDir,
+		Logger:                    func(...any) {}, // no-op logger
+		Timeout:                   10 * time.Second,
+		ConcurrencyPolicy:         policy,
+	})
+}
+
+// TestConcurrencyPolicyCancelPrevious tests that a new build cancels the running one
+func TestConcurrencyPolicyCancelPrevious(t *testing.T) {
+	compiler := newPolicyCompiler(t, CancelPrevious)
+
+	first := make(chan error, 1)
+	go func() { first <- compiler.CompileProgram() }()
+	waitFor(t, 2*time.Second, compiler.IsCompiling)
+
+	if err := compiler.CompileProgram(); err != nil {
+		t.Errorf("Second compilation failed: %v", err)
+	}
+	if err := <-first; !errors.Is(err, ErrCanceled) {
+		t.Errorf("Expected first compilation to be canceled, got %v", err)
+	}
+}
+
+// TestConcurrencyPolicyQueue tests that every requested build runs to completion, one at a time
+func TestConcurrencyPolicyQueue(t *testing.T) {
+	compiler := newPolicyCompiler(t, Queue)
+
+	const numGoroutines = 3
+	var wg sync.WaitGroup
+	errs := make([]error, numGoroutines)
+
+	for i := range numGoroutines {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			errs[index] = compiler.CompileProgram()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Goroutine %d: queued compilation failed: %v", i, err)
+		}
+	}
+
+	history := compiler.History()
+	if len(history) != numGoroutines {
+		t.Fatalf("Expected %d builds, got %d", numGoroutines, len(history))
+	}
+	for i := 1; i < len(history); i++ {
+		if history[i].StartTime.Before(history[i-1].EndTime) {
+			t.Errorf("Build %d started before build %d finished", history[i].ID, history[i-1].ID)
+		}
+	}
+}
+
+// TestConcurrencyPolicyQueueCancelWhileWaiting tests that a queued build can be canceled before it starts
+func TestConcurrencyPolicyQueueCancelWhileWaiting(t *testing.T) {
+	compiler := newPolicyCompiler(t, Queue)
+
+	running := compiler.Start()
+	queued := compiler.Start()
+	queued.Cancel()
+
+	if _, err := queued.Wait(); !errors.Is(err, ErrCanceled) {
+		t.Errorf("Expected queued build to be canceled, got %v", err)
+	}
+	if _, err := running.Wait(); err != nil {
+		t.Errorf("Running build should not be affected, got %v", err)
+	}
+}
+
+// TestConcurrencyPolicyDropIfBusy tests that builds requested while busy are refused
+func TestConcurrencyPolicyDropIfBusy(t *testing.T) {
+	compiler := newPolicyCompiler(t, DropIfBusy)
+
+	first := compiler.Start()
+
+	if err := compiler.CompileProgram(); !errors.Is(err, ErrBusy) {
+		t.Errorf("Expected ErrBusy from CompileProgram, got %v", err)
+	}
+	if _, err := compiler.CompileToMemory(); !errors.Is(err, ErrBusy) {
+		t.Errorf("Expected ErrBusy from CompileToMemory, got %v", err)
+	}
+	if _, err := compiler.Start().Wait(); !errors.Is(err, ErrBusy) {
+		t.Errorf("Expected ErrBusy from Start, got %v", err)
+	}
+
+	if _, err := first.Wait(); err != nil {
+		t.Errorf("Running build should not be affected, got %v", err)
+	}
+	if history := compiler.History(); len(history) != 1 {
+		t.Errorf("Expected only the first build to run, got %d", len(history))
+	}
+}
+
+// TestConcurrencyPolicyJoinInFlight tests that concurrent callers share one build
+func TestConcurrencyPolicyJoinInFlight(t *testing.T) {
+	compiler := newPolicyCompiler(t, JoinInFlight)
+
+	first := compiler.Start()
+	joined := compiler.Start()
+
+	if joined.ID() != first.ID() {
+		t.Errorf("Expected Start to return the in-flight handle %d, got %d", first.ID(), joined.ID())
+	}
+
+	const numGoroutines = 4
+	var wg sync.WaitGroup
+	results := make([]*BuildResult, numGoroutines)
+	errs := make([]error, numGoroutines)
+
+	for i := range numGoroutines {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			results[index], errs[index] = compiler.CompileProgramResult()
+		}(i)
+	}
+	wg.Wait()
+
+	firstResult, err := first.Wait()
+	if err != nil {
+		t.Fatalf("First build failed: %v", err)
+	}
+
+	for i := range numGoroutines {
+		if errs[i] != nil || results[i] != firstResult {
+			t.Errorf("Goroutine %d: expected the shared result, got %p (%v)", i, results[i], errs[i])
+		}
+	}
+	if history := compiler.History(); len(history) != 1 {
+		t.Errorf("Expected a single build, got %d", len(history))
+	}
+}
@@ -0,0 +1,130 @@
+package gobuild
+
+import (
+	"bytes"
+	"context"
+	"go/build"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ShouldRecompile reports whether a change to path (absolute or relative to AppRootDir)
+// can affect this build, eg: web/main_wasm.go is false for a server built for linux
+// The package graph is listed with GOOS/GOARCH from Env, or the tinygo -target, and -tags from CompilingArguments
+// Deleted files are true when they were inputs of the previous call, or when their name
+// matches the build constraints inside a package directory of the graph
+// When the graph cannot be listed it answers true, a spare build is cheaper than a stale one
+func (h *GoBuild) ShouldRecompile(path string) bool {
+	pkgs, err := h.listPackages(context.Background())
+	if err != nil {
+		return true
+	}
+
+	path = h.resolveInputPath(path)
+
+	inputs := map[string]bool{}
+	for _, file := range inputFiles(pkgs) {
+		inputs[resolvePath(file)] = true
+	}
+
+	h.mu.Lock()
+	known := h.knownInputs[path]
+	h.knownInputs = inputs
+	h.mu.Unlock()
+
+	if inputs[path] || known {
+		return true
+	}
+	if fileExists(path) {
+		return false
+	}
+	return h.matchesDeletedFile(pkgs, path)
+}
+
+// matchesDeletedFile applies the file name constraints of path, eg: _js.go or _wasm.go
+// Only directory-scanned local packages are considered, files named on the command line
+// form a package without a directory scan
+func (h *GoBuild) matchesDeletedFile(pkgs []listedPackage, path string) bool {
+	dir, name := filepath.Dir(path), filepath.Base(path)
+	if strings.HasSuffix(name, "_test.go") {
+		return false
+	}
+
+	ctxt := h.buildContext()
+	// the content is gone, only the name can be matched
+	ctxt.OpenFile = func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader([]byte("package p\n"))), nil
+	}
+
+	for _, p := range pkgs {
+		if !p.local() || p.ImportPath == "command-line-arguments" {
+			continue
+		}
+		if pkgDir, err := filepath.EvalSymlinks(p.Dir); err != nil || pkgDir != dir {
+			continue
+		}
+		match, err := ctxt.MatchFile(dir, name)
+		return err == nil && match
+	}
+	return false
+}
+
+// buildContext returns the go/build context matching Env and the -tags of CompilingArguments
+// A tinygo target decides GOOS, GOARCH and adds its own tags, eg: -target=wasm is js/wasm
+func (h *GoBuild) buildContext() build.Context {
+	ctxt := build.Default
+	for _, env := range h.config.Env {
+		key, value, _ := strings.Cut(env, "=")
+		switch key {
+		case "GOOS":
+			ctxt.GOOS = value
+		case "GOARCH":
+			ctxt.GOARCH = value
+		case "CGO_ENABLED":
+			ctxt.CgoEnabled = value == "1"
+		}
+	}
+
+	var tags []string
+	flags := h.listFlags()
+	for i := 0; i < len(flags); i++ {
+		name, value, hasValue := strings.Cut(flags[i], "=")
+		if name != "-tags" {
+			continue
+		}
+		if !hasValue && i+1 < len(flags) {
+			i++
+			value = flags[i]
+		}
+		tags = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+
+	if target, ok := h.tinyGoTargetInfo(); ok {
+		ctxt.GOOS, ctxt.GOARCH = target.GOOS, target.GOARCH
+		tags = append(slices.Clone(target.BuildTags), tags...)
+	}
+	ctxt.BuildTags = tags
+	return ctxt
+}
+
+// resolveInputPath makes path absolute from AppRootDir and resolves symlinks
+func (h *GoBuild) resolveInputPath(path string) string {
+	if !filepath.IsAbs(path) {
+		if root, err := filepath.Abs(h.config.AppRootDir); err == nil {
+			path = filepath.Join(root, path)
+		}
+	}
+	return resolvePath(path)
+}
+
+// resolvePath resolves the symlinks of the directory of path, the file itself may be deleted
+// eg: /tmp on macOS is /private/tmp in go list output
+func resolvePath(path string) string {
+	dir, name := filepath.Split(filepath.Clean(path))
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	return filepath.Join(dir, name)
+}
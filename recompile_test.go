@@ -0,0 +1,105 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShouldRecompile(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"go.mod":                "module example.com/app\n\ngo 1.22\n",
+		"web/main.go":           "package main\n\nimport _ \"example.com/app/shared\"\n\nfunc main() {}\n",
+		"server/main.go":        "package main\n\nimport _ \"example.com/app/shared\"\n\nfunc main() {}\n",
+		"shared/shared.go":      "package shared\n",
+		"shared/shared_wasm.go": "package shared\n",
+		"shared/tagged.go":      "//go:build js\n\npackage shared\n",
+		"shared/debug.go":       "//go:build debug\n\npackage shared\n",
+		"shared/shared_test.go": "package shared\n",
+		"README.md":             "docs",
+	})
+
+	wasm := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "web/main.go",
+		OutName:                   "main",
+		Env:                       []string{"GOOS=js", "GOARCH=wasm"},
+	})
+	server := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "server/main.go",
+		OutName:                   "server",
+		Env:                       []string{"GOOS=linux", "GOARCH=amd64"},
+	})
+	debugServer := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "server/main.go",
+		OutName:                   "server",
+		Env:                       []string{"GOOS=linux", "GOARCH=amd64"},
+		CompilingArguments:        func() []string { return []string{"-tags", "debug"} },
+	})
+
+	cases := []struct {
+		path                     string
+		wasm, server, debugBuild bool
+	}{
+		{"go.mod", true, true, true},
+		{"shared/shared.go", true, true, true},
+		{"shared/shared_wasm.go", true, false, false},
+		{"shared/tagged.go", true, false, false},
+		{"shared/debug.go", false, false, true},
+		{"shared/gone_js.go", true, false, false}, // deleted, matched by name
+		{"shared/gone_linux.go", false, true, true},
+		{"shared/shared_test.go", false, false, false},
+		{"web/main.go", true, false, false},
+		{"server/main.go", false, true, true},
+		{"README.md", false, false, false},
+		{filepath.Join(tempDir, "shared", "shared_wasm.go"), true, false, false},
+	}
+
+	for _, c := range cases {
+		if got := wasm.ShouldRecompile(c.path); got != c.wasm {
+			t.Errorf("wasm ShouldRecompile(%s) = %v, want %v", c.path, got, c.wasm)
+		}
+		if got := server.ShouldRecompile(c.path); got != c.server {
+			t.Errorf("server ShouldRecompile(%s) = %v, want %v", c.path, got, c.server)
+		}
+		if got := debugServer.ShouldRecompile(c.path); got != c.debugBuild {
+			t.Errorf("debug server ShouldRecompile(%s) = %v, want %v", c.path, got, c.debugBuild)
+		}
+	}
+}
+
+func TestShouldRecompileDeletedInput(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"go.mod":         "module example.com/app\n\ngo 1.22\n",
+		"web/main.go":    "package main\n\nimport _ \"example.com/app/shared\"\n\nfunc main() {}\n",
+		"shared/a.go":    "package shared\n",
+		"shared/util.go": "//go:build js\n\npackage shared\n",
+	})
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "web/main.go",
+		OutName:                   "main",
+		Env:                       []string{"GOOS=js", "GOARCH=wasm"},
+	})
+
+	if !gb.ShouldRecompile("shared/util.go") {
+		t.Fatal("Expected shared/util.go to be an input")
+	}
+
+	// The name has no constraint, only the previous graph knows it was an input
+	if err := os.Remove(filepath.Join(tempDir, "shared", "util.go")); err != nil {
+		t.Fatal(err)
+	}
+	if !gb.ShouldRecompile("shared/util.go") {
+		t.Error("Expected a deleted input to trigger a rebuild")
+	}
+}
@@ -0,0 +1,52 @@
+package gobuild
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// BuildResult describes a successful compilation
+// eg: "built in 1.8s, 2.1 MB, hash abc123"
+type BuildResult struct {
+	StartTime  time.Time
+	Duration   time.Duration
+	OutputPath string   // promoted file, eg: web/build/main.wasm. Empty for in-memory builds
+	Bytes      []byte   // artifact of in-memory builds, nil for disk builds
+	Size       int64    // artifact size in bytes
+	SHA256     string   // hex encoded artifact hash
+	Args       []string // full argv, eg: go build -o web/build/main_temp.wasm web/main.go
+	Env        []string // environment of the toolchain process
+	Dir        string   // working directory of the toolchain process
+	Output     string   // raw toolchain output
+	Warnings   []Diagnostic
+	CacheHit   bool                 // restored from Config.BuildCacheDir, the toolchain did not run
+	Unchanged  bool                 // identical to the current output, left untouched (Config.SkipIdenticalOutput)
+	Profile    *BuildProfile        // per-package timing with Config.Profile, nil otherwise
+	Compressed []CompressedArtifact // one entry per Config.Compressors
+	Processed  []PostProcessStep    // one entry per Config.PostProcessors step
+}
+
+// newBuildResult records how the toolchain of comp was invoked
+func newBuildResult(comp *compilation) *BuildResult {
+	return &BuildResult{
+		StartTime: comp.startTime,
+		Args:      append([]string(nil), comp.cmd.Args...),
+		Env:       comp.cmd.Environ(),
+		Dir:       comp.cmd.Dir,
+	}
+}
+
+// setOutput keeps the raw toolchain output and the diagnostics it reported
+// A successful build only reports warnings
+func (r *BuildResult) setOutput(h *GoBuild, output []byte) {
+	r.Output = string(output)
+	r.Warnings = h.parseDiagnostics(r.Output)
+}
+
+// setArtifact records size and hash of the produced binary
+func (r *BuildResult) setArtifact(data []byte) {
+	sum := sha256.Sum256(data)
+	r.Size = int64(len(data))
+	r.SHA256 = hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,149 @@
+package gobuild
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeMainGo creates a minimal main package in dir and returns the main.go path
+func writeMainGo(t *testing.T, dir, message string) string {
+	t.Helper()
+	mainGoPath := filepath.Join(dir, "main.go")
+	content := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"" + message + "\")\n}\n"
+	if err := os.WriteFile(mainGoPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create main.go: %v", err)
+	}
+	return mainGoPath
+}
+
+func TestCompileProgramResult(t *testing.T) {
+	tempDir := t.TempDir()
+	writeMainGo(t, tempDir, "Hello from result test!")
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "resultapp",
+		Extension:                 getExecutableExtension(),
+		OutFolderRelativePath:     tempDir,
+		Env:                       []string{"CGO_ENABLED=0"},
+		Timeout:                   30 * time.Second,
+	})
+
+	result, err := gb.CompileProgramResult()
+	if err != nil {
+		t.Fatalf("CompileProgramResult failed: %v", err)
+	}
+
+	if result.OutputPath != gb.FinalOutputPath() {
+		t.Errorf("Expected OutputPath %s, got %s", gb.FinalOutputPath(), result.OutputPath)
+	}
+
+	data, err := os.ReadFile(result.OutputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	sum := sha256.Sum256(data)
+
+	if result.Size != int64(len(data)) {
+		t.Errorf("Expected Size %d, got %d", len(data), result.Size)
+	}
+	if result.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected SHA256 of the promoted file, got %s", result.SHA256)
+	}
+	if result.Bytes != nil {
+		t.Error("Disk builds should not keep the artifact bytes")
+	}
+	if result.Duration <= 0 || result.StartTime.IsZero() {
+		t.Errorf("Expected timing to be recorded, got start=%v duration=%v", result.StartTime, result.Duration)
+	}
+	if len(result.Args) < 2 || result.Args[0] != "go" || result.Args[1] != "build" {
+		t.Errorf("Expected argv to start with 'go build', got %v", result.Args)
+	}
+	if result.Dir != tempDir {
+		t.Errorf("Expected Dir %s, got %s", tempDir, result.Dir)
+	}
+	if result.Env[len(result.Env)-1] != "CGO_ENABLED=0" {
+		t.Errorf("Expected Config.Env to be part of the recorded environment, got %v", result.Env[len(result.Env)-1])
+	}
+}
+
+func TestCompileToMemoryResult(t *testing.T) {
+	tempDir := t.TempDir()
+	writeMainGo(t, tempDir, "Hello from memory result test!")
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "resultapp",
+		Timeout:                   30 * time.Second,
+	})
+
+	result, err := gb.CompileToMemoryResult()
+	if err != nil {
+		t.Fatalf("CompileToMemoryResult failed: %v", err)
+	}
+
+	sum := sha256.Sum256(result.Bytes)
+	if len(result.Bytes) == 0 || result.Size != int64(len(result.Bytes)) {
+		t.Errorf("Expected Size to match the in-memory artifact, got %d for %d bytes", result.Size, len(result.Bytes))
+	}
+	if result.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected SHA256 of the in-memory artifact, got %s", result.SHA256)
+	}
+	if result.OutputPath != "" {
+		t.Errorf("In-memory builds have no OutputPath, got %s", result.OutputPath)
+	}
+	if gb.IsCompiling() {
+		t.Error("IsCompiling should be false after CompileToMemoryResult returns")
+	}
+}
+
+func TestSkipIdenticalOutput(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, fakeBuildScript)
+
+	config := &Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "same",
+		OutFolderRelativePath:     tempDir,
+		SkipIdenticalOutput:       true,
+	}
+	gb := New(config)
+
+	if result, err := gb.CompileProgramResult(); err != nil || result.Unchanged {
+		t.Fatalf("First build: expected a promoted output, got %+v %v", result, err)
+	}
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(gb.FinalOutputPath(), past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := gb.CompileProgramResult()
+	if err != nil {
+		t.Fatalf("Second build failed: %v", err)
+	}
+	if !result.Unchanged {
+		t.Error("Expected Unchanged for a byte-identical build")
+	}
+	if info, err := os.Stat(gb.FinalOutputPath()); err != nil || !info.ModTime().Equal(past) {
+		t.Errorf("Expected the final file to be left alone, got %v %v", info.ModTime(), err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(tempDir, "same_temp*")); len(matches) != 0 {
+		t.Errorf("Expected the temp file to be removed, found %v", matches)
+	}
+
+	// Different bytes are promoted as usual
+	config.Command = writeFakeToolchain(t, t.TempDir(), fakeBuildScript+`; echo changed >> "$out"`)
+	if result, err := New(config).CompileProgramResult(); err != nil || result.Unchanged {
+		t.Errorf("Expected a changed build to be promoted, got %+v %v", result, err)
+	}
+}
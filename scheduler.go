@@ -0,0 +1,96 @@
+package gobuild
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDebounce is the quiet period used when Config.Debounce is not set
+const defaultDebounce = 100 * time.Millisecond
+
+// scheduler coalesces RequestBuild calls into debounced builds
+type scheduler struct {
+	mu       sync.Mutex
+	timer    *time.Timer  // pending quiet period, nil when idle
+	pending  []string     // reasons waiting for the next build
+	running  *BuildHandle // build started by the scheduler
+	trailing bool         // quiet period elapsed while running, build again when it ends
+	watchers map[*watcher]bool
+}
+
+// RequestBuild schedules a disk build once no new request arrived for Config.Debounce
+// Bursts are merged into one build whose reasons are kept in History(), eg: "save main.go, save util.go"
+// While a scheduled build runs, new requests produce at most one trailing build after it
+// Results are reported through Config.Callback
+func (h *GoBuild) RequestBuild(reason string) {
+	s := h.scheduler
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !slices.Contains(s.pending, reason) {
+		s.pending = append(s.pending, reason)
+	}
+
+	if s.timer != nil {
+		s.timer.Reset(h.debounce())
+		return
+	}
+	s.timer = time.AfterFunc(h.debounce(), h.quietPeriodElapsed)
+}
+
+func (h *GoBuild) debounce() time.Duration {
+	if h.config.Debounce > 0 {
+		return h.config.Debounce
+	}
+	return defaultDebounce
+}
+
+// quietPeriodElapsed starts the pending build or defers it until the running one ends
+func (h *GoBuild) quietPeriodElapsed() {
+	s := h.scheduler
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.timer = nil
+	if s.running != nil {
+		s.trailing = true
+		return
+	}
+	h.launchScheduled()
+}
+
+// launchScheduled starts a build for the pending reasons, s.mu must be held
+func (h *GoBuild) launchScheduled() {
+	s := h.scheduler
+	if len(s.pending) == 0 {
+		return
+	}
+
+	reason := strings.Join(s.pending, ", ")
+	s.pending = nil
+	s.trailing = false
+
+	handle := h.start(context.Background(), reason)
+	s.running = handle
+
+	go func() {
+		<-handle.Done()
+
+		s.mu.Lock()
+		s.running = nil
+		// a still running timer will launch the trailing build itself
+		if s.trailing && s.timer == nil {
+			h.launchScheduled()
+		}
+		watchers := s.subscribed()
+		s.mu.Unlock()
+
+		result, err := handle.Wait()
+		for _, w := range watchers {
+			w.send(WatchEvent{ID: handle.ID(), Reason: reason, Result: result, Err: err})
+		}
+	}()
+}
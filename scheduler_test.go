@@ -0,0 +1,88 @@
+package gobuild
+
+import (
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds or the timeout expires
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condition not met before timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRequestBuildCoalescesBurst(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, fakeBuildScript)
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "schedapp",
+		OutFolderRelativePath:     tempDir,
+		Debounce:                  50 * time.Millisecond,
+	})
+
+	for _, reason := range []string{"save main.go", "save util.go", "save main.go", "save view.go"} {
+		gb.RequestBuild(reason)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	waitFor(t, 5*time.Second, func() bool { return len(gb.History()) == 1 && !gb.IsCompiling() })
+
+	// Leave time for a wrong second build to show up
+	time.Sleep(150 * time.Millisecond)
+
+	history := gb.History()
+	if len(history) != 1 {
+		t.Fatalf("Expected the burst to produce 1 build, got %d", len(history))
+	}
+	if history[0].Reason != "save main.go, save util.go, save view.go" {
+		t.Errorf("Expected merged reasons, got %q", history[0].Reason)
+	}
+	if !history[0].Success {
+		t.Errorf("Scheduled build failed: %v", history[0].Err)
+	}
+}
+
+func TestRequestBuildSingleTrailingBuild(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeToolchain(t, tempDir, "sleep 0.4\n"+fakeBuildScript)
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "schedapp",
+		OutFolderRelativePath:     tempDir,
+		Debounce:                  30 * time.Millisecond,
+	})
+
+	gb.RequestBuild("first")
+	waitFor(t, 2*time.Second, gb.IsCompiling)
+
+	// Requests during the running build must not cancel it
+	gb.RequestBuild("second")
+	time.Sleep(100 * time.Millisecond)
+	gb.RequestBuild("third")
+	time.Sleep(100 * time.Millisecond)
+
+	waitFor(t, 5*time.Second, func() bool { return len(gb.History()) == 2 && !gb.IsCompiling() })
+	time.Sleep(150 * time.Millisecond)
+
+	history := gb.History()
+	if len(history) != 2 {
+		t.Fatalf("Expected the running build plus one trailing build, got %d: %+v", len(history), history)
+	}
+	if history[0].Reason != "first" || !history[0].Success {
+		t.Errorf("Expected first build to finish uncanceled, got %+v", history[0])
+	}
+	if history[1].Reason != "second, third" || !history[1].Success {
+		t.Errorf("Expected one trailing build for the queued reasons, got %+v", history[1])
+	}
+}
@@ -0,0 +1,385 @@
+package gobuild
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// sizeReportRows is the number of functions and packages String prints
+const sizeReportRows = 20
+
+// wasmSectionNames indexes the known wasm section ids, custom sections (0) use their own name
+var wasmSectionNames = []string{"custom", "type", "import", "function", "table", "memory", "global", "export", "start", "element", "code", "data", "datacount", "tag"}
+
+// SizeReport breaks a wasm artifact down by section, function and Go package
+// eg: fmt.Print(report) prints the sections and the top contributors as a table
+type SizeReport struct {
+	Total     int64
+	Sections  []SectionSize  // in file order, headers included so they add up to Total minus the 8 byte preamble
+	Functions []FunctionSize // code bodies, largest first
+	Packages  []PackageSize  // code bodies summed by symbol prefix, largest first
+}
+
+// SectionSize is one wasm section, eg: {ID: 0, Name: "name", Size: 81920}
+type SectionSize struct {
+	ID   byte
+	Name string // eg: code, data, or the custom section name
+	Size int64
+}
+
+// FunctionSize is one code body, eg: {Name: "fmt.(*pp).printArg", Package: "fmt", Size: 4096}
+// Name is "func[<index>]" when the artifact has no name section
+type FunctionSize struct {
+	Name    string
+	Package string
+	Size    int64
+}
+
+// PackageSize sums the code bodies of one package, eg: {Name: "runtime", Size: 409600, Functions: 812}
+type PackageSize struct {
+	Name      string
+	Size      int64
+	Functions int
+}
+
+// SizeReport parses the latest artifact, the in-memory one first, then FinalOutputPath
+// Symbols are attributed to the import paths listed for the main package, the go
+// linker writes them mangled in the name section, eg: github.com_a_b.F is github.com/a/b
+func (h *GoBuild) SizeReport() (*SizeReport, error) {
+	h.mu.RLock()
+	data := h.memoryBytes
+	h.mu.RUnlock()
+
+	if len(data) == 0 {
+		var err error
+		if data, err = os.ReadFile(h.FinalOutputPath()); err != nil {
+			return nil, fmt.Errorf("size report: %w", err)
+		}
+	}
+
+	// without a package list symbols fall back to their prefix
+	var paths map[string]string
+	if pkgs, err := h.listPackages(context.Background()); err == nil {
+		paths = map[string]string{}
+		for _, p := range pkgs {
+			paths[p.ImportPath] = p.ImportPath
+			paths[mangleSymbol(p.ImportPath)] = p.ImportPath
+		}
+	}
+	return newSizeReport(data, paths)
+}
+
+// NewSizeReport parses a wasm artifact, eg: NewSizeReport(result.Bytes)
+// Packages are guessed from the symbol prefix and the go linker mangling is undone,
+// eg: internal_strconv is internal/strconv; a path element holding '_' or a dot after
+// the domain (gopkg.in/yaml.v3) can be misread, SizeReport resolves those from go list
+// Truncated or non wasm data returns an *ArtifactError
+func NewSizeReport(data []byte) (*SizeReport, error) {
+	return newSizeReport(data, nil)
+}
+
+// newSizeReport parses data, paths maps symbol prefixes to import paths, see SizeReport
+func newSizeReport(data []byte, paths map[string]string) (*SizeReport, error) {
+	if err := validateWasm(data); err != nil {
+		return nil, fmt.Errorf("size report: %w", err)
+	}
+
+	report := &SizeReport{Total: int64(len(data))}
+	var bodies []int64
+	var names map[uint64]string
+	imported := 0
+
+	for offset := 8; offset < len(data); {
+		id := data[offset]
+		size, n := binary.Uvarint(data[offset+1:])
+		start := offset + 1 + n
+		payload := &wasmReader{data: data[start : start+int(size)], base: start}
+
+		section := SectionSize{ID: id, Name: wasmSectionNames[id], Size: int64(1 + n + int(size))}
+		switch id {
+		case 0:
+			section.Name = payload.name()
+			if section.Name == "name" {
+				names = payload.functionNames()
+			}
+		case 2:
+			imported = payload.importedFunctions()
+		case 10:
+			bodies = payload.codeBodies()
+		}
+		if payload.err != nil {
+			return nil, fmt.Errorf("size report: %w", payload.err)
+		}
+
+		report.Sections = append(report.Sections, section)
+		offset = start + int(size)
+	}
+
+	packages := map[string]*PackageSize{}
+	for i, size := range bodies {
+		index := uint64(imported + i)
+		name, pkg := names[index], "(unnamed)"
+		if name == "" {
+			name = fmt.Sprintf("func[%d]", index)
+		} else {
+			pkg = symbolPackage(name, paths)
+		}
+		report.Functions = append(report.Functions, FunctionSize{Name: name, Package: pkg, Size: size})
+
+		if packages[pkg] == nil {
+			packages[pkg] = &PackageSize{Name: pkg}
+		}
+		packages[pkg].Size += size
+		packages[pkg].Functions++
+	}
+	for _, p := range packages {
+		report.Packages = append(report.Packages, *p)
+	}
+
+	sort.SliceStable(report.Functions, func(i, j int) bool { return report.Functions[i].Size > report.Functions[j].Size })
+	sort.Slice(report.Packages, func(i, j int) bool {
+		a, b := report.Packages[i], report.Packages[j]
+		return a.Size > b.Size || a.Size == b.Size && a.Name < b.Name
+	})
+	return report, nil
+}
+
+// String renders the sections and the top contributors as tables, eg:
+//
+//	SECTION  SIZE     SHARE
+//	code     1.2 MB   58.3%
+func (r *SizeReport) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	share := func(size int64) string {
+		return fmt.Sprintf("%.1f%%", float64(size)*100/float64(r.Total))
+	}
+
+	fmt.Fprintln(w, "SECTION\tSIZE\tSHARE")
+	for _, s := range r.Sections {
+		name := s.Name
+		if s.ID == 0 {
+			name = "custom " + name
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, formatSize(s.Size), share(s.Size))
+	}
+	fmt.Fprintf(w, "total\t%s\t\n", formatSize(r.Total))
+
+	fmt.Fprintln(w, "\nPACKAGE\tSIZE\tSHARE\tFUNCS")
+	for _, p := range r.Packages[:min(len(r.Packages), sizeReportRows)] {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", p.Name, formatSize(p.Size), share(p.Size), p.Functions)
+	}
+	if more := len(r.Packages) - sizeReportRows; more > 0 {
+		fmt.Fprintf(w, "... %d more\t\t\t\n", more)
+	}
+
+	fmt.Fprintln(w, "\nFUNCTION\tSIZE\tSHARE")
+	for _, f := range r.Functions[:min(len(r.Functions), sizeReportRows)] {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", f.Name, formatSize(f.Size), share(f.Size))
+	}
+	if more := len(r.Functions) - sizeReportRows; more > 0 {
+		fmt.Fprintf(w, "... %d more\t\t\n", more)
+	}
+
+	w.Flush()
+	return buf.String()
+}
+
+// typeHelpers labels the compiler generated type:.eq and type:.hash functions
+const typeHelpers = "(type helpers)"
+
+// symbolPackage returns the package of a symbol, the longest prefix found in paths first
+// eg: "github.com/a/b.(*T).M" gives "github.com/a/b", "(*main.T).M" gives "main"
+// Without a match mangled prefixes are demangled, eg: "github.com_a_b.F" gives "github.com/a/b"
+func symbolPackage(name string, paths map[string]string) string {
+	name = strings.TrimLeft(name, "(*")
+	if strings.HasPrefix(name, "type:.") || strings.HasPrefix(name, "type_.") {
+		return typeHelpers
+	}
+
+	// generic instances may carry other import paths, eg: slices.Sort[...github.com/a/b.T]
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i]
+	}
+
+	for end := len(name); end > 0; end = strings.LastIndexByte(name[:end], '.') {
+		if path, ok := paths[name[:end]]; ok && end < len(name) {
+			return path
+		}
+	}
+
+	start := strings.LastIndexByte(name, '/') + 1
+	if start > 0 {
+		// tinygo keeps the slashes
+		if dot := strings.IndexByte(name[start:], '.'); dot >= 0 {
+			return name[:start+dot]
+		}
+		return "(no package)"
+	}
+
+	// a mangled module path keeps the dots of its domain, eg: github.com_a_b.F
+	if first, _, ok := strings.Cut(name, "_"); ok && isDomain(first) {
+		start = len(first)
+	}
+	dot := strings.IndexByte(name[start:], '.')
+	if dot < 0 {
+		return "(no package)"
+	}
+	return strings.ReplaceAll(name[:start+dot], "_", "/")
+}
+
+// moduleTLDs are the top-level domains recognized in mangled module paths, eg: github.com
+// A plain word would also match lowercase functions, eg: runtime.mapassign_faststr
+var moduleTLDs = []string{"com", "org", "net", "io", "dev", "in", "me", "co", "app", "cloud", "sh", "xyz"}
+
+// isDomain reports whether s looks like the first element of a module path, eg: github.com
+func isDomain(s string) bool {
+	dot := strings.LastIndexByte(s, '.')
+	if dot <= 0 || !slices.Contains(moduleTLDs, s[dot+1:]) {
+		return false
+	}
+	for _, r := range s {
+		if r != '.' && r != '-' && (r < '0' || r > '9') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// mangleSymbol replaces what the go linker strips from wasm names, eg: github.com/a/b gives github.com_a_b
+func mangleSymbol(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// wasmReader decodes a section payload, the first out of bounds read sets err
+type wasmReader struct {
+	data []byte
+	base int // offset of data in the artifact, for errors
+	off  int
+	err  error
+}
+
+func (r *wasmReader) fail() {
+	if r.err == nil {
+		r.err = &ArtifactError{Format: FormatWasm, Offset: int64(r.base + r.off), Reason: "malformed section content"}
+	}
+	r.off = len(r.data)
+}
+
+func (r *wasmReader) byte() byte {
+	if r.off >= len(r.data) {
+		r.fail()
+		return 0
+	}
+	r.off++
+	return r.data[r.off-1]
+}
+
+func (r *wasmReader) uleb() uint64 {
+	v, n := binary.Uvarint(r.data[r.off:])
+	if n <= 0 {
+		r.fail()
+		return 0
+	}
+	r.off += n
+	return v
+}
+
+func (r *wasmReader) name() string {
+	size := r.uleb()
+	if size > uint64(len(r.data)-r.off) {
+		r.fail()
+		return ""
+	}
+	r.off += int(size)
+	return string(r.data[r.off-int(size) : r.off])
+}
+
+// limits skips a table or memory limits entry
+func (r *wasmReader) limits() {
+	if flags := r.byte(); flags&1 != 0 {
+		r.uleb()
+		r.uleb()
+	} else {
+		r.uleb()
+	}
+}
+
+// importedFunctions counts the function imports, they come first in the function index space
+func (r *wasmReader) importedFunctions() int {
+	functions := 0
+	for count := r.uleb(); count > 0 && r.err == nil; count-- {
+		r.name() // module
+		r.name() // field
+		switch kind := r.byte(); kind {
+		case 0: // func: type index
+			r.uleb()
+			functions++
+		case 1: // table: reftype + limits
+			r.byte()
+			r.limits()
+		case 2: // memory
+			r.limits()
+		case 3: // global: valtype + mutability
+			r.byte()
+			r.byte()
+		case 4: // tag: attribute + type index
+			r.byte()
+			r.uleb()
+		default:
+			r.fail()
+		}
+	}
+	return functions
+}
+
+// codeBodies returns the size of each code body, its size prefix included
+func (r *wasmReader) codeBodies() []int64 {
+	var sizes []int64
+	for count := r.uleb(); count > 0 && r.err == nil; count-- {
+		start := r.off
+		size := r.uleb()
+		if size > uint64(len(r.data)-r.off) {
+			r.fail()
+			break
+		}
+		r.off += int(size)
+		sizes = append(sizes, int64(r.off-start))
+	}
+	return sizes
+}
+
+// functionNames decodes the function names subsection (1) of the name section
+// A malformed name section is ignored, it only affects the labels
+func (r *wasmReader) functionNames() map[uint64]string {
+	names := map[uint64]string{}
+	for r.off < len(r.data) && r.err == nil {
+		id := r.byte()
+		size := r.uleb()
+		if size > uint64(len(r.data)-r.off) {
+			break
+		}
+		if id != 1 {
+			r.off += int(size)
+			continue
+		}
+		for count := r.uleb(); count > 0 && r.err == nil; count-- {
+			index := r.uleb()
+			names[index] = r.name()
+		}
+	}
+	r.err = nil
+	return names
+}
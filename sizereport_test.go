@@ -0,0 +1,179 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wasmSection encodes a section whose payload is under 128 bytes
+func wasmSection(id byte, payload ...byte) []byte {
+	return append([]byte{id, byte(len(payload))}, payload...)
+}
+
+// wasmName encodes a name under 128 bytes
+func wasmName(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+func TestNewSizeReport(t *testing.T) {
+	names := []byte{0x02, 0x01}
+	names = append(names, wasmName("main.main")...)
+	names = append(names, 0x02)
+	names = append(names, wasmName("github.com/a/b.(*T).M")...)
+	nameSection := append(wasmName("name"), wasmSection(1, names...)...)
+
+	imports := append([]byte{0x01}, wasmName("env")...)
+	imports = append(imports, wasmName("f")...)
+	imports = append(imports, 0x00, 0x00)
+
+	data := []byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}
+	data = append(data, wasmSection(1, 0x01, 0x60, 0x00, 0x00)...)
+	data = append(data, wasmSection(2, imports...)...)
+	data = append(data, wasmSection(3, 0x02, 0x00, 0x00)...)
+	data = append(data, wasmSection(10, 0x02, 0x02, 0x00, 0x0b, 0x04, 0x00, 0x01, 0x01, 0x0b)...)
+	data = append(data, wasmSection(0, nameSection...)...)
+
+	report, err := NewSizeReport(data)
+	if err != nil {
+		t.Fatalf("NewSizeReport failed: %v", err)
+	}
+
+	var total int64 = 8
+	for _, s := range report.Sections {
+		total += s.Size
+	}
+	if total != report.Total || report.Total != int64(len(data)) {
+		t.Errorf("Expected sections to add up to %d bytes, got %d", len(data), total)
+	}
+	if last := report.Sections[len(report.Sections)-1]; last.ID != 0 || last.Name != "name" {
+		t.Errorf("Expected the custom section to be named, got %+v", last)
+	}
+
+	expected := []FunctionSize{
+		{Name: "github.com/a/b.(*T).M", Package: "github.com/a/b", Size: 5},
+		{Name: "main.main", Package: "main", Size: 3},
+	}
+	if len(report.Functions) != 2 || report.Functions[0] != expected[0] || report.Functions[1] != expected[1] {
+		t.Errorf("Expected functions %+v after the import, got %+v", expected, report.Functions)
+	}
+	if len(report.Packages) != 2 || report.Packages[0].Name != "github.com/a/b" || report.Packages[0].Functions != 1 {
+		t.Errorf("Expected packages sorted by size, got %+v", report.Packages)
+	}
+
+	table := report.String()
+	for _, want := range []string{"SECTION", "custom name", "PACKAGE", "github.com/a/b", "FUNCTION", "main.main"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("Expected the table to contain %q, got:\n%s", want, table)
+		}
+	}
+
+	var artifactErr *ArtifactError
+	if _, err := NewSizeReport(data[:len(data)-4]); !errors.As(err, &artifactErr) {
+		t.Errorf("Expected a truncated artifact to return an *ArtifactError, got %v", err)
+	}
+}
+
+func TestSymbolPackage(t *testing.T) {
+	paths := map[string]string{"github.com_a_b": "github.com/a/b", "internal_abi": "internal/abi"}
+
+	tests := map[string]string{
+		"runtime.mallocgc":                 "runtime",
+		"(*main.T).Method":                 "main",
+		"github.com/a/b.(*T).M":            "github.com/a/b",
+		"slices.Sort[...github.com/a/b.T]": "slices",
+		"fmt.(*pp).printArg":               "fmt",
+		"malloc":                           "(no package)",
+
+		// go linker names, see mangleSymbol
+		"runtime.__mheap_.alloc":       "runtime",
+		"slices.Sort_go.shape.string_": "slices",
+		"github.com_c_d.init.func1":    "github.com/c/d",
+		"internal_runtime_maps.F":      "internal/runtime/maps",
+		"runtime.mapassign_faststr":    "runtime",
+		"runtime.park_m.func1":         "runtime",
+		"go.uber.org_zap.New":          "go.uber.org/zap",
+		"type_.eq.main.T":              typeHelpers,
+		"type:.hash.[2]string":         typeHelpers,
+		"github.com_a_b.__T_.M":        "github.com/a/b",
+		"internal_abi.__Type_.Kind":    "internal/abi",
+	}
+	for name, want := range tests {
+		if got := symbolPackage(name, paths); got != want {
+			t.Errorf("symbolPackage(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if got := mangleSymbol("github.com/a/b.(*T).M"); got != "github.com_a_b.__T_.M" {
+		t.Errorf("Expected the go linker mangling, got %q", got)
+	}
+}
+
+func TestSizeReportFromDiskAndMemory(t *testing.T) {
+	tempDir := t.TempDir()
+	writeMainGo(t, tempDir, "Hello from size report test!")
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     tempDir,
+		Env:                       []string{"GOOS=js", "GOARCH=wasm"},
+		Timeout:                   60 * time.Second,
+	})
+
+	if _, err := gb.SizeReport(); err == nil {
+		t.Error("Expected an error before the first build")
+	}
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("CompileProgram failed: %v", err)
+	}
+	disk, err := gb.SizeReport()
+	if err != nil {
+		t.Fatalf("SizeReport from disk failed: %v", err)
+	}
+	if len(disk.Functions) == 0 || disk.Functions[0].Size < disk.Functions[len(disk.Functions)-1].Size {
+		t.Errorf("Expected functions sorted by size, got %d functions", len(disk.Functions))
+	}
+
+	found := map[string]bool{}
+	for _, p := range disk.Packages {
+		found[p.Name] = true
+	}
+	for _, want := range []string{"runtime", "main", "internal/abi"} {
+		if !found[want] {
+			t.Errorf("Expected %s to be attributed by import path, got %+v", want, disk.Packages[:min(len(disk.Packages), 5)])
+		}
+	}
+
+	if _, err := gb.CompileToMemory(); err != nil {
+		t.Fatalf("CompileToMemory failed: %v", err)
+	}
+	memory, err := gb.SizeReport()
+	if err != nil {
+		t.Fatalf("SizeReport from memory failed: %v", err)
+	}
+	if memory.Total == 0 || len(memory.Functions) != len(disk.Functions) {
+		t.Errorf("Expected the in-memory report to match the disk one, got %d and %d functions", len(memory.Functions), len(disk.Functions))
+	}
+
+	// without go list the std paths are demangled to the same names
+	data, err := os.ReadFile(gb.FinalOutputPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	standalone, err := NewSizeReport(data)
+	if err != nil {
+		t.Fatalf("NewSizeReport failed: %v", err)
+	}
+	for i, p := range standalone.Packages {
+		if p != disk.Packages[i] {
+			t.Errorf("Expected %+v like SizeReport, got %+v", disk.Packages[i], p)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package gobuild
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// TinyGoOptions are the typed tinygo build flags, see Config.TinyGo
+// Empty fields are left to the tinygo defaults
+type TinyGoOptions struct {
+	Target    string // eg: wasm, wasip1, pico
+	Opt       string // optimization level, eg: s, z, 2
+	GC        string // eg: leaking, conservative, precise
+	Scheduler string // eg: none, tasks, asyncify
+	Panic     string // eg: print, trap
+	NoDebug   bool   // strip debug information, smaller wasm
+	StackSize string // default goroutine stack size, eg: 16KB
+}
+
+// goOnlyFlags are go build flags tinygo does not understand
+var goOnlyFlags = []string{
+	"-a", "-asan", "-asmflags", "-buildmode", "-buildvcs", "-compiler", "-cover", "-covermode",
+	"-coverpkg", "-gccgoflags", "-gcflags", "-installsuffix", "-linkshared", "-mod", "-modfile",
+	"-msan", "-pgo", "-pkgdir", "-race", "-toolexec", "-trimpath",
+}
+
+// tinyGoValueFlags take the next argument as their value unless written with "="
+// eg: -tags race, the value is not a flag of its own
+var tinyGoValueFlags = []string{
+	"-X", "-gc", "-ldflags", "-o", "-opt", "-p", "-panic", "-scheduler", "-serial",
+	"-size", "-stack-size", "-tags", "-target",
+}
+
+// tinyGoTarget is the part of `tinygo info -json` describing a target
+type tinyGoTarget struct {
+	GOOS      string   `json:"goos"`
+	GOARCH    string   `json:"goarch"`
+	BuildTags []string `json:"build_tags"`
+}
+
+// knownTinyGoTargets answer when `tinygo info` is unavailable, eg: the wasm targets
+var knownTinyGoTargets = map[string]tinyGoTarget{
+	"wasm":   {GOOS: "js", GOARCH: "wasm", BuildTags: []string{"tinygo", "wasm"}},
+	"wasi":   {GOOS: "wasip1", GOARCH: "wasm", BuildTags: []string{"tinygo", "wasm", "wasi"}},
+	"wasip1": {GOOS: "wasip1", GOARCH: "wasm", BuildTags: []string{"tinygo", "wasm", "wasip1"}},
+	"wasip2": {GOOS: "wasip2", GOARCH: "wasm", BuildTags: []string{"tinygo", "wasm", "wasip2"}},
+}
+
+// tinyGoTargetName returns the -target of a tinygo build, "" for go or the host target
+// CompilingArguments come after Config.TinyGo in the build, the last one wins
+func (h *GoBuild) tinyGoTargetName() string {
+	if !h.isTinyGo() {
+		return ""
+	}
+	var target string
+	flags := h.listFlags()
+	for i := 0; i < len(flags); i++ {
+		name, value, hasValue := strings.Cut(flags[i], "=")
+		if name != "-target" {
+			continue
+		}
+		if !hasValue && i+1 < len(flags) {
+			i++
+			value = flags[i]
+		}
+		target = value
+	}
+	return target
+}
+
+// tinyGoTargetInfo returns GOOS, GOARCH and the build tags of the tinygo target
+// It runs `tinygo info` once per target and instance, false without a target
+func (h *GoBuild) tinyGoTargetInfo() (tinyGoTarget, bool) {
+	name := h.tinyGoTargetName()
+	if name == "" {
+		return tinyGoTarget{}, false
+	}
+
+	h.profilerMu.Lock()
+	defer h.profilerMu.Unlock()
+	if target, ok := h.tinyGoTargets[name]; ok {
+		return target, target.GOOS != ""
+	}
+
+	cmd := exec.CommandContext(context.Background(), h.config.Command, "info", "-json", "-target="+name)
+	cmd.Dir = h.config.AppRootDir
+	cmd.Env = h.buildEnv()
+
+	var target tinyGoTarget
+	out, err := cmd.Output()
+	if err != nil || json.Unmarshal(out, &target) != nil || target.GOOS == "" {
+		// unknown targets are remembered too, they keep the host context
+		target = knownTinyGoTargets[name]
+	}
+
+	if h.tinyGoTargets == nil {
+		h.tinyGoTargets = map[string]tinyGoTarget{}
+	}
+	h.tinyGoTargets[name] = target
+	return target, target.GOOS != ""
+}
+
+// args returns the options as tinygo flags, eg: -target=wasm -opt=z -no-debug
+func (o *TinyGoOptions) args() []string {
+	if o == nil {
+		return nil
+	}
+
+	var args []string
+	for _, f := range []struct{ name, value string }{
+		{"-target", o.Target},
+		{"-opt", o.Opt},
+		{"-gc", o.GC},
+		{"-scheduler", o.Scheduler},
+		{"-panic", o.Panic},
+		{"-stack-size", o.StackSize},
+	} {
+		if f.value != "" {
+			args = append(args, f.name+"="+f.value)
+		}
+	}
+	if o.NoDebug {
+		args = append(args, "-no-debug")
+	}
+	return args
+}
+
+// checkArguments rejects flags the configured toolchain does not support
+// eg: -race with tinygo, or Config.TinyGo with the go command
+func (h *GoBuild) checkArguments() error {
+	if !h.isTinyGo() {
+		if h.config.TinyGo != nil {
+			return &BuildError{Kind: ErrInvalidArguments, Err: fmt.Errorf("tinygo options require the tinygo command, got %q", h.config.Command)}
+		}
+		return nil
+	}
+
+	if h.config.CompilingArguments == nil {
+		return nil
+	}
+	args := h.config.CompilingArguments()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, _, hasValue := strings.Cut(arg, "=")
+		name = "-" + strings.TrimLeft(name, "-")
+		if slices.Contains(goOnlyFlags, name) {
+			return &BuildError{Kind: ErrInvalidArguments, Err: fmt.Errorf("%s is a go build flag, tinygo does not support it", arg)}
+		}
+		if !hasValue && slices.Contains(tinyGoValueFlags, name) {
+			i++
+		}
+	}
+	return nil
+}
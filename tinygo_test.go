@@ -0,0 +1,220 @@
+package gobuild
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestTinyGoOptionsArguments verifies that TinyGoOptions become tinygo flags in buildArguments
+func TestTinyGoOptionsArguments(t *testing.T) {
+	testCases := []struct {
+		name           string
+		options        *TinyGoOptions
+		args           []string
+		expectedInArgs []string
+	}{
+		{
+			name:           "no_options",
+			options:        nil,
+			expectedInArgs: []string{"build", "-o", "/tmp/temp_test", "test.go"},
+		},
+		{
+			name:           "target_only",
+			options:        &TinyGoOptions{Target: "wasm"},
+			expectedInArgs: []string{"build", "-target=wasm", "-o", "/tmp/temp_test", "test.go"},
+		},
+		{
+			name: "all_options",
+			options: &TinyGoOptions{
+				Target:    "wasm",
+				Opt:       "z",
+				GC:        "leaking",
+				Scheduler: "none",
+				Panic:     "trap",
+				NoDebug:   true,
+				StackSize: "16KB",
+			},
+			expectedInArgs: []string{"build", "-target=wasm", "-opt=z", "-gc=leaking", "-scheduler=none", "-panic=trap", "-stack-size=16KB", "-no-debug", "-o", "/tmp/temp_test", "test.go"},
+		},
+		{
+			name:           "options_with_arguments",
+			options:        &TinyGoOptions{Target: "wasm", NoDebug: true},
+			args:           []string{"-tags=dev", "-X main.version=1.0.0"},
+			expectedInArgs: []string{"build", "-target=wasm", "-no-debug", "-tags=dev", "-ldflags=-X main.version=1.0.0", "-o", "/tmp/temp_test", "test.go"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			compiler := New(&Config{
+				Command:                   "tinygo",
+				MainInputFileRelativePath: "test.go",
+				OutName:                   "test",
+				OutFolderRelativePath:     "/tmp",
+				TinyGo:                    tc.options,
+				CompilingArguments:        func() []string { return tc.args },
+			})
+
+			if err := compiler.checkArguments(); err != nil {
+				t.Fatalf("Unexpected argument error: %v", err)
+			}
+
+			buildArgs := compiler.buildArguments("temp_test")
+			if strings.Join(buildArgs, " | ") != strings.Join(tc.expectedInArgs, " | ") {
+				t.Errorf("Expected: %v", tc.expectedInArgs)
+				t.Errorf("Got:      %v", buildArgs)
+			}
+		})
+	}
+}
+
+// TestTinyGoRejectsGoOnlyFlags verifies the clear error for flags tinygo does not support
+func TestTinyGoRejectsGoOnlyFlags(t *testing.T) {
+	for _, arg := range []string{"-race", "-gcflags=-N -l", "--trimpath", "-buildmode=c-shared"} {
+		t.Run(arg, func(t *testing.T) {
+			compiler := New(&Config{
+				Command:                   "tinygo",
+				MainInputFileRelativePath: "test.go",
+				OutName:                   "test",
+				OutFolderRelativePath:     t.TempDir(),
+				TinyGo:                    &TinyGoOptions{Target: "wasm"},
+				CompilingArguments:        func() []string { return []string{"-X main.version=1", arg} },
+			})
+
+			// rejected before the toolchain is looked up
+			err := compiler.CompileProgram()
+			if !errors.Is(err, ErrInvalidArguments) {
+				t.Fatalf("Expected ErrInvalidArguments, got %v", err)
+			}
+			if !strings.Contains(err.Error(), arg) {
+				t.Errorf("Expected the flag in the error, got %v", err)
+			}
+
+			if _, err := compiler.CompileToMemory(); !errors.Is(err, ErrInvalidArguments) {
+				t.Errorf("CompileToMemory: expected ErrInvalidArguments, got %v", err)
+			}
+		})
+	}
+
+	// Values are not flags, eg: the race build tag
+	for _, args := range [][]string{{"-target", "wasm", "-tags", "race"}, {"-tags a"}, {"-tags", "a"}, {"--tags", "trimpath"}} {
+		t.Run(fmt.Sprintf("%q", args), func(t *testing.T) {
+			compiler := New(&Config{
+				Command:            "tinygo",
+				CompilingArguments: func() []string { return args },
+			})
+			if err := compiler.checkArguments(); err != nil {
+				t.Errorf("Expected %q to be accepted, got %v", args, err)
+			}
+		})
+	}
+}
+
+func TestTinyGoOptionsRequireTinyGo(t *testing.T) {
+	compiler := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "test.go",
+		OutName:                   "test",
+		OutFolderRelativePath:     t.TempDir(),
+		TinyGo:                    &TinyGoOptions{Target: "wasm"},
+	})
+
+	if err := compiler.CompileProgram(); !errors.Is(err, ErrInvalidArguments) {
+		t.Errorf("Expected ErrInvalidArguments for TinyGo options with go, got %v", err)
+	}
+}
+
+// writeFakeTinyGo creates a tinygo command answering info for the pico target and
+// list through the go toolchain, -target=wasm becomes GOOS=js GOARCH=wasm
+func writeFakeTinyGo(t *testing.T, dir, calls string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake tinygo requires a unix shell")
+	}
+
+	script := `#!/bin/sh
+echo "$@" >> "` + calls + `"
+if [ "$1" = "info" ]; then
+	[ "$3" = "-target=pico" ] || exit 1
+	echo '{"goos":"linux","goarch":"arm","build_tags":["tinygo","baremetal","rp2040"]}'
+	exit 0
+fi
+if [ "$1" = "list" ]; then
+	for arg in "$@"; do
+		shift
+		case "$arg" in
+		-target=wasm) export GOOS=js GOARCH=wasm ;;
+		-target=*) ;;
+		*) set -- "$@" "$arg" ;;
+		esac
+	done
+	exec go "$@"
+fi
+exit 1
+`
+	tool := filepath.Join(dir, "bin", "tinygo")
+	writeFiles(t, dir, map[string]string{"bin/tinygo": script})
+	if err := os.Chmod(tool, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return tool
+}
+
+// TestTinyGoTargetPackageGraph verifies the -target reaches package listing and build constraints
+func TestTinyGoTargetPackageGraph(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"go.mod":           "module example.com/app\n\ngo 1.22\n",
+		"web/main.go":      "package main\n\nimport _ \"example.com/app/ui\"\n\nfunc main() {}\n",
+		"ui/ui.go":         "package ui\n",
+		"ui/dom_js.go":     "package ui\n",
+		"ui/host_linux.go": "package ui\n",
+	})
+	calls := filepath.Join(tempDir, "calls.log")
+
+	config := &Config{
+		AppRootDir:                tempDir,
+		Command:                   writeFakeTinyGo(t, tempDir, calls),
+		MainInputFileRelativePath: "web/main.go",
+		OutName:                   "main",
+		TinyGo:                    &TinyGoOptions{Target: "wasm"},
+	}
+	gb := New(config)
+
+	files, err := gb.InputFiles()
+	if err != nil {
+		t.Fatalf("InputFiles failed: %v", err)
+	}
+	if !slices.Contains(files, filepath.Join(tempDir, "ui/dom_js.go")) || slices.Contains(files, filepath.Join(tempDir, "ui/host_linux.go")) {
+		t.Errorf("Expected the wasm package graph, got %v", files)
+	}
+
+	// tinygo info fails here, the known wasm target answers
+	ctxt := gb.buildContext()
+	if ctxt.GOOS != "js" || ctxt.GOARCH != "wasm" || !slices.Contains(ctxt.BuildTags, "tinygo") {
+		t.Errorf("Expected js/wasm with the tinygo tag, got %s/%s %v", ctxt.GOOS, ctxt.GOARCH, ctxt.BuildTags)
+	}
+	if !gb.ShouldRecompile("ui/new_js.go") || gb.ShouldRecompile("ui/new_linux.go") {
+		t.Error("Expected build constraints of the wasm target")
+	}
+
+	// other targets are described by tinygo info, run once per target
+	config.TinyGo = nil
+	config.CompilingArguments = func() []string { return []string{"-target", "pico", "-tags=dev"} }
+	for range 2 {
+		ctxt = gb.buildContext()
+	}
+	if ctxt.GOOS != "linux" || ctxt.GOARCH != "arm" || strings.Join(ctxt.BuildTags, ",") != "tinygo,baremetal,rp2040,dev" {
+		t.Errorf("Expected the pico target with -tags, got %s/%s %v", ctxt.GOOS, ctxt.GOARCH, ctxt.BuildTags)
+	}
+	data, _ := os.ReadFile(calls)
+	if strings.Count(string(data), "info -json -target=pico") != 1 {
+		t.Errorf("Expected tinygo info to run once, got calls:\n%s", data)
+	}
+}
@@ -0,0 +1,193 @@
+package gobuild
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"slices"
+)
+
+// Artifact formats checked before promotion, see Config.ValidateOutput
+const (
+	FormatWasm  = "wasm"
+	FormatELF   = "elf"
+	FormatMachO = "macho"
+	FormatPE    = "pe"
+)
+
+// ArtifactError describes why an artifact was rejected before promotion
+// It is the Err of a *BuildError whose Kind is ErrInvalidArtifact
+type ArtifactError struct {
+	Format string // expected format, eg: wasm
+	Offset int64  // byte offset of the problem, eg: the truncated section
+	Reason string // eg: section 10 exceeds the file (truncated)
+}
+
+func (e *ArtifactError) Error() string {
+	return fmt.Sprintf("%s artifact: %s at offset %d", e.Format, e.Reason, e.Offset)
+}
+
+// validateArtifact rejects truncated or corrupt artifacts before promotion
+// wasm is checked unless Config.SkipWasmValidation, native binaries with Config.ValidateOutput
+// Formats it does not know are promoted as is, eg: aix XCOFF, plan9, tinygo firmware
+func (h *GoBuild) validateArtifact(data []byte) error {
+	format := h.artifactFormat()
+	var err error
+	switch {
+	case format == FormatWasm:
+		if h.config.SkipWasmValidation {
+			return nil
+		}
+		err = validateWasm(data)
+	case !h.config.ValidateOutput:
+		return nil
+	case format == FormatELF:
+		err = validateELF(data)
+	case format == FormatMachO:
+		err = validateMachO(data)
+	case format == FormatPE:
+		err = validatePE(data)
+	}
+	if err != nil {
+		return &BuildError{Kind: ErrInvalidArtifact, Err: err}
+	}
+	return nil
+}
+
+// elfGOOS are the GOOS values whose binaries are ELF files
+var elfGOOS = []string{"android", "dragonfly", "freebsd", "illumos", "linux", "netbsd", "openbsd", "solaris"}
+
+// artifactFormat returns the format the toolchain produces for the configured target, "" when unknown
+// eg: GOARCH=wasm or tinygo -target=wasm give wasm, GOOS=windows gives pe
+func (h *GoBuild) artifactFormat() string {
+	if target, ok := h.tinyGoTargetInfo(); ok {
+		// firmware targets write elf, hex or uf2 files depending on the extension
+		if target.GOARCH == "wasm" {
+			return FormatWasm
+		}
+		return ""
+	}
+
+	env := h.buildEnv()
+	goos, goarch := envValue(env, "GOOS"), envValue(env, "GOARCH")
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+
+	switch {
+	case goarch == "wasm" || goos == "js":
+		return FormatWasm
+	case goos == "darwin" || goos == "ios":
+		return FormatMachO
+	case goos == "windows":
+		return FormatPE
+	case slices.Contains(elfGOOS, goos):
+		return FormatELF
+	}
+	return ""
+}
+
+// validateWasm checks the magic, the version and that every section fits the file exactly
+func validateWasm(data []byte) error {
+	fail := func(offset int, reason string, args ...any) error {
+		return &ArtifactError{Format: FormatWasm, Offset: int64(offset), Reason: fmt.Sprintf(reason, args...)}
+	}
+
+	if len(data) < 8 || !bytes.Equal(data[:4], []byte("\x00asm")) {
+		return fail(0, "missing \\0asm magic")
+	}
+	if version := binary.LittleEndian.Uint32(data[4:8]); version != 1 {
+		return fail(4, "unsupported version %d", version)
+	}
+
+	seen := map[byte]bool{}
+	hasCode := false
+	for offset := 8; offset < len(data); {
+		id := data[offset]
+		size, n := binary.Uvarint(data[offset+1:])
+		if n <= 0 {
+			return fail(offset, "section %d has an invalid size (truncated)", id)
+		}
+		start := offset + 1 + n
+		if size > uint64(len(data)-start) {
+			return fail(offset, "section %d exceeds the file (truncated)", id)
+		}
+
+		// known sections appear at most once, custom sections (0) may repeat
+		if id > 13 {
+			return fail(offset, "unknown section id %d", id)
+		}
+		if id != 0 && seen[id] {
+			return fail(offset, "duplicate section %d", id)
+		}
+		seen[id] = true
+		hasCode = hasCode || id == 10
+
+		offset = start + int(size)
+	}
+
+	if !hasCode {
+		return fail(len(data), "no code section")
+	}
+	return nil
+}
+
+// validateELF parses the headers and checks the section data lies within the file
+func validateELF(data []byte) error {
+	f, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return &ArtifactError{Format: FormatELF, Reason: err.Error()}
+	}
+	defer f.Close()
+
+	for _, s := range f.Sections {
+		if s.Type != elf.SHT_NOBITS && s.Offset+s.FileSize > uint64(len(data)) {
+			return &ArtifactError{Format: FormatELF, Offset: int64(s.Offset), Reason: fmt.Sprintf("section %s exceeds the file (truncated)", s.Name)}
+		}
+	}
+	for _, p := range f.Progs {
+		if p.Off+p.Filesz > uint64(len(data)) {
+			return &ArtifactError{Format: FormatELF, Offset: int64(p.Off), Reason: "program segment exceeds the file (truncated)"}
+		}
+	}
+	return nil
+}
+
+// validateMachO parses the load commands and checks the segments lie within the file
+func validateMachO(data []byte) error {
+	f, err := macho.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return &ArtifactError{Format: FormatMachO, Reason: err.Error()}
+	}
+	defer f.Close()
+
+	for _, l := range f.Loads {
+		if s, ok := l.(*macho.Segment); ok && s.Offset+s.Filesz > uint64(len(data)) {
+			return &ArtifactError{Format: FormatMachO, Offset: int64(s.Offset), Reason: fmt.Sprintf("segment %s exceeds the file (truncated)", s.Name)}
+		}
+	}
+	return nil
+}
+
+// validatePE parses the headers and checks the section data lies within the file
+func validatePE(data []byte) error {
+	f, err := pe.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return &ArtifactError{Format: FormatPE, Reason: err.Error()}
+	}
+	defer f.Close()
+
+	for _, s := range f.Sections {
+		if uint64(s.Offset)+uint64(s.Size) > uint64(len(data)) {
+			return &ArtifactError{Format: FormatPE, Offset: int64(s.Offset), Reason: fmt.Sprintf("section %s exceeds the file (truncated)", s.Name)}
+		}
+	}
+	return nil
+}
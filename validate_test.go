@@ -0,0 +1,167 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// minimalWasm is a module with an empty type, function and code section
+var minimalWasm = []byte{
+	0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00,
+	0x01, 0x04, 0x01, 0x60, 0x00, 0x00, // type: func() -> ()
+	0x03, 0x02, 0x01, 0x00, // function: 1 of type 0
+	0x0a, 0x04, 0x01, 0x02, 0x00, 0x0b, // code: empty body
+}
+
+// fakeWasmBuildScript writes minimalWasm to the -o destination, see fakeBuildScript
+const fakeWasmBuildScript = `while [ $# -gt 0 ]; do
+	if [ "$1" = "-o" ]; then shift; out="$1"; fi
+	shift
+done
+printf '\0asm\1\0\0\0\1\4\1\140\0\0\3\2\1\0\12\4\1\2\0\13' > "$out"`
+
+func TestValidateWasm(t *testing.T) {
+	if err := validateWasm(minimalWasm); err != nil {
+		t.Fatalf("Expected a minimal module to be valid, got %v", err)
+	}
+
+	tests := map[string][]byte{
+		"empty":     {},
+		"magic":     append([]byte("\x7fELF"), minimalWasm[4:]...),
+		"version":   append(append([]byte{}, minimalWasm[:4]...), 0x02, 0x00, 0x00, 0x00),
+		"truncated": minimalWasm[:len(minimalWasm)-2],
+		"duplicate": append(append([]byte{}, minimalWasm...), 0x03, 0x02, 0x01, 0x00),
+		"unknown":   append(append([]byte{}, minimalWasm...), 0x20, 0x00),
+		"no code":   minimalWasm[:18],
+	}
+	for name, data := range tests {
+		err := validateWasm(data)
+		var artifactErr *ArtifactError
+		if !errors.As(err, &artifactErr) || artifactErr.Format != FormatWasm {
+			t.Errorf("%s: expected a wasm *ArtifactError, got %v", name, err)
+		}
+	}
+}
+
+func TestValidateOutputRejectsTruncatedWasm(t *testing.T) {
+	tempDir := t.TempDir()
+	truncated := filepath.Join(tempDir, "truncated.wasm")
+	if err := os.WriteFile(truncated, minimalWasm[:len(minimalWasm)-3], 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool := writeFakeToolchain(t, tempDir, `while [ $# -gt 0 ]; do
+	if [ "$1" = "-o" ]; then shift; out="$1"; fi
+	shift
+done
+if [ "$out" = "/dev/stdout" ]; then cat "`+truncated+`"; else cp "`+truncated+`" "$out"; fi`)
+
+	outDir := filepath.Join(tempDir, "public")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	finalPath := filepath.Join(outDir, "main.wasm")
+	if err := os.WriteFile(finalPath, minimalWasm, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     outDir,
+		Env:                       []string{"GOOS=js", "GOARCH=wasm"},
+	})
+
+	err := gb.CompileProgram()
+	var artifactErr *ArtifactError
+	if !errors.Is(err, ErrInvalidArtifact) || !errors.As(err, &artifactErr) {
+		t.Fatalf("Expected ErrInvalidArtifact with an *ArtifactError, got %v", err)
+	}
+
+	if data, err := os.ReadFile(finalPath); err != nil || string(data) != string(minimalWasm) {
+		t.Errorf("Expected the previous main.wasm to be left untouched, got %d bytes %v", len(data), err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(outDir, "main_temp*")); len(matches) != 0 {
+		t.Errorf("Expected the temp file to be removed, found %v", matches)
+	}
+
+	if _, err := gb.CompileToMemory(); !errors.Is(err, ErrInvalidArtifact) {
+		t.Errorf("CompileToMemory: expected ErrInvalidArtifact, got %v", err)
+	}
+}
+
+func TestValidateOutputNative(t *testing.T) {
+	tempDir := t.TempDir()
+	writeMainGo(t, tempDir, "Hello from validate test!")
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "validapp",
+		Extension:                 getExecutableExtension(),
+		OutFolderRelativePath:     tempDir,
+		Env:                       []string{"CGO_ENABLED=0"},
+		Timeout:                   30 * time.Second,
+		ValidateOutput:            true,
+	})
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Expected a real build to pass validation, got %v", err)
+	}
+
+	data, err := os.ReadFile(gb.FinalOutputPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gb.validateArtifact(data[:len(data)/2]); !errors.Is(err, ErrInvalidArtifact) {
+		t.Errorf("Expected a truncated %s binary to be rejected, got %v", gb.artifactFormat(), err)
+	}
+}
+
+func TestValidateOutputDefaults(t *testing.T) {
+	tempDir := t.TempDir()
+	config := &Config{
+		Command:                   writeFakeToolchain(t, tempDir, fakeBuildScript),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     tempDir,
+		Env:                       []string{"GOOS=js", "GOARCH=wasm"},
+	}
+
+	// wasm is checked without any option
+	if err := New(config).CompileProgram(); !errors.Is(err, ErrInvalidArtifact) {
+		t.Errorf("Expected a non wasm artifact to be rejected by default, got %v", err)
+	}
+
+	config.SkipWasmValidation = true
+	if err := New(config).CompileProgram(); err != nil {
+		t.Errorf("Expected SkipWasmValidation to promote the artifact, got %v", err)
+	}
+
+	config.Command = writeFakeToolchain(t, tempDir, fakeWasmBuildScript)
+	config.SkipWasmValidation = false
+	if err := New(config).CompileProgram(); err != nil {
+		t.Errorf("Expected a valid module to be promoted, got %v", err)
+	}
+
+	// native binaries only with ValidateOutput, unknown formats never
+	for _, env := range [][]string{{"GOOS=linux"}, {"GOOS=aix", "GOARCH=ppc64"}, {"GOOS=plan9", "GOARCH=amd64"}} {
+		native := New(&Config{
+			Command:                   writeFakeToolchain(t, tempDir, fakeBuildScript),
+			MainInputFileRelativePath: "main.go",
+			OutName:                   "server",
+			OutFolderRelativePath:     tempDir,
+			Env:                       env,
+			ValidateOutput:            env[0] != "GOOS=linux",
+		})
+		if err := native.CompileProgram(); err != nil {
+			t.Errorf("%v: expected the artifact to be promoted, got %v", env, err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package gobuild
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// wasmExecName is the JavaScript support file required by GOOS=js binaries
+const wasmExecName = "wasm_exec.js"
+
+// targetsJS reports whether the build produces a GOOS=js binary
+// eg: Env GOOS=js, or tinygo with -target=wasm from TinyGo or CompilingArguments
+func (h *GoBuild) targetsJS() bool {
+	if target, ok := h.tinyGoTargetInfo(); ok {
+		return target.GOOS == "js"
+	}
+	return envValue(h.buildEnv(), "GOOS") == "js"
+}
+
+// wasmExecState remembers where wasm_exec.js comes from and what was last provisioned
+type wasmExecState struct {
+	source string
+	err    error  // lookup failure, reported once per instance
+	stamp  string // source and destination fileStamp after the last copy
+}
+
+// provisionWasmExec copies the wasm_exec.js of the active toolchain next to
+// FinalOutputPath, the file is left untouched when its content is the same
+// The toolchain is asked once per instance, later builds only stat both files
+func (h *GoBuild) provisionWasmExec() error {
+	if !h.targetsJS() {
+		return nil
+	}
+
+	h.profilerMu.Lock()
+	defer h.profilerMu.Unlock()
+	if h.wasmExec == nil {
+		source, err := h.locateWasmExec()
+		h.wasmExec = &wasmExecState{source: source, err: err}
+		if err != nil {
+			return err
+		}
+	}
+	if h.wasmExec.err != nil {
+		return nil
+	}
+
+	src := h.wasmExec.source
+	dst := filepath.Join(h.config.OutFolderRelativePath, wasmExecName)
+	if stamp := fileStamp(src) + "|" + fileStamp(dst); stamp == h.wasmExec.stamp {
+		return nil
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if current, err := os.ReadFile(dst); err != nil || !bytes.Equal(current, data) {
+		if err := writeFileAtomic(dst, data); err != nil {
+			return err
+		}
+	}
+	h.wasmExec.stamp = fileStamp(src) + "|" + fileStamp(dst)
+	return nil
+}
+
+// locateWasmExec returns the wasm_exec.js shipped with the toolchain
+// go: $GOROOT/lib/wasm (go 1.24+) or $GOROOT/misc/wasm, tinygo: $TINYGOROOT/targets
+func (h *GoBuild) locateWasmExec() (string, error) {
+	rootVar, candidates := "GOROOT", []string{"lib/wasm", "misc/wasm"}
+	if h.isTinyGo() {
+		rootVar, candidates = "TINYGOROOT", []string{"targets"}
+	}
+
+	cmd := exec.Command(h.config.Command, "env", rootVar)
+	cmd.Dir = h.config.AppRootDir
+	cmd.Env = h.buildEnv()
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s env %s: %w", h.config.Command, rootVar, err)
+	}
+
+	root := strings.TrimSpace(string(out))
+	for _, dir := range candidates {
+		if file := filepath.Join(root, filepath.FromSlash(dir), wasmExecName); fileExists(file) {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in %s %s", wasmExecName, rootVar, root)
+}
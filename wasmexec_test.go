@@ -0,0 +1,187 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFakeGoRoot creates a GOROOT holding wasm_exec.js under dir and a toolchain reporting it
+func writeFakeGoRoot(t *testing.T, dir, wasmDir, content string) string {
+	t.Helper()
+	root := filepath.Join(dir, "goroot")
+	writeFiles(t, root, map[string]string{filepath.Join(wasmDir, "wasm_exec.js"): content})
+	return writeFakeToolchain(t, dir, `if [ "$1" = "env" ]; then echo "`+root+`"; exit 0; fi
+`+fakeWasmBuildScript)
+}
+
+func TestWasmExecProvisioning(t *testing.T) {
+	tempDir := t.TempDir()
+	outDir := filepath.Join(tempDir, "public")
+	tool := writeFakeGoRoot(t, tempDir, "lib/wasm", "// go 1.24 support\n")
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     outDir,
+		Env:                       []string{"GOOS=js", "GOARCH=wasm"},
+	})
+	os.MkdirAll(outDir, 0755)
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	wasmExec := filepath.Join(outDir, "wasm_exec.js")
+	if data, err := os.ReadFile(wasmExec); err != nil || string(data) != "// go 1.24 support\n" {
+		t.Fatalf("Expected wasm_exec.js next to the output, got %q %v", data, err)
+	}
+	if !slices.Contains(gb.UnobservedFiles(), "wasm_exec.js") {
+		t.Errorf("Expected wasm_exec.js in UnobservedFiles, got %v", gb.UnobservedFiles())
+	}
+
+	// Same content: the file is not rewritten
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(wasmExec, past, past)
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Second build failed: %v", err)
+	}
+	if info, _ := os.Stat(wasmExec); !info.ModTime().Equal(past) {
+		t.Error("Expected an identical wasm_exec.js to be left untouched")
+	}
+
+	// Toolchain upgrade: the new content replaces the old one
+	os.WriteFile(filepath.Join(tempDir, "goroot", "lib", "wasm", "wasm_exec.js"), []byte("// upgraded\n"), 0644)
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Third build failed: %v", err)
+	}
+	if data, _ := os.ReadFile(wasmExec); string(data) != "// upgraded\n" {
+		t.Errorf("Expected the upgraded wasm_exec.js, got %q", data)
+	}
+}
+
+func TestWasmExecMiscWasmFallback(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeGoRoot(t, tempDir, "misc/wasm", "// older go\n")
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     tempDir,
+		Env:                       []string{"GOOS=js", "GOARCH=wasm"},
+	})
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tempDir, "wasm_exec.js")); string(data) != "// older go\n" {
+		t.Errorf("Expected wasm_exec.js from misc/wasm, got %q", data)
+	}
+}
+
+func TestWasmExecOnlyForJS(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := writeFakeGoRoot(t, tempDir, "lib/wasm", "// support\n")
+
+	gb := New(&Config{
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "server",
+		OutFolderRelativePath:     tempDir,
+		Env:                       []string{"GOOS=linux"},
+	})
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "wasm_exec.js")); !os.IsNotExist(err) {
+		t.Error("wasm_exec.js must only be provisioned for GOOS=js")
+	}
+	if slices.Contains(gb.UnobservedFiles(), "wasm_exec.js") {
+		t.Error("wasm_exec.js must only be unobserved for GOOS=js")
+	}
+}
+
+func TestWasmExecTinyGoTarget(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts *TinyGoOptions
+		args []string
+		js   bool
+	}{
+		{"options", &TinyGoOptions{Target: "wasm"}, nil, true},
+		{"arguments", nil, []string{"-target", "wasm"}, true},
+		{"arguments with value", nil, []string{"-target=wasm"}, true},
+		{"wasi", nil, []string{"-target", "wasip1"}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gb := New(&Config{
+				AppRootDir:                t.TempDir(),
+				Command:                   "tinygo",
+				MainInputFileRelativePath: "main.go",
+				OutName:                   "main",
+				Extension:                 ".wasm",
+				TinyGo:                    tc.opts,
+				CompilingArguments:        func() []string { return tc.args },
+			})
+			if got := slices.Contains(gb.UnobservedFiles(), "wasm_exec.js"); got != tc.js {
+				t.Errorf("Expected wasm_exec.js unobserved %v, got %v", tc.js, got)
+			}
+		})
+	}
+}
+
+func TestWasmExecLookedUpOnce(t *testing.T) {
+	tempDir := t.TempDir()
+	writeMainGo(t, tempDir, "Hello from wasm_exec test!")
+	root := filepath.Join(tempDir, "goroot")
+	writeFiles(t, root, map[string]string{"lib/wasm/wasm_exec.js": "// support\n"})
+
+	calls := filepath.Join(tempDir, "calls.log")
+	tool := writeFakeToolchain(t, tempDir, `echo "$1" >> "`+calls+`"
+if [ "$1" = "env" ]; then echo "`+root+`"; exit 0; fi
+`+fakeWasmBuildScript)
+
+	outDir := filepath.Join(tempDir, "public")
+	os.MkdirAll(outDir, 0755)
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   tool,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     outDir,
+		Env:                       []string{"GOOS=js", "GOARCH=wasm"},
+		BuildCacheDir:             filepath.Join(tempDir, "cache"),
+		Timeout:                   30 * time.Second,
+	})
+
+	for i := range 3 {
+		if _, err := gb.CompileProgramResult(); err != nil {
+			t.Fatalf("Build %d failed: %v", i, err)
+		}
+	}
+
+	// one real build and one lookup, the cache hits spawn nothing
+	data, _ := os.ReadFile(calls)
+	if string(data) != "build\nenv\n" {
+		t.Errorf("Expected a single env lookup and no process on cache hits, got calls %q", data)
+	}
+
+	// a deleted copy is restored without asking the toolchain again
+	os.Remove(filepath.Join(outDir, "wasm_exec.js"))
+	gb.CompileProgramResult()
+	if _, err := os.Stat(filepath.Join(outDir, "wasm_exec.js")); err != nil {
+		t.Errorf("Expected wasm_exec.js to be restored: %v", err)
+	}
+	if data, _ := os.ReadFile(calls); strings.Count(string(data), "env") != 1 {
+		t.Errorf("Expected the toolchain to be asked once, got calls %q", data)
+	}
+}
@@ -0,0 +1,235 @@
+package gobuild
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultWatchInterval is the polling period used when Config.WatchInterval is not set
+const defaultWatchInterval = 300 * time.Millisecond
+
+// maxPendingWatchEvents bounds the events kept for a Watch channel nobody drains
+const maxPendingWatchEvents = 16
+
+// WatchEvent reports the outcome of a build scheduled by Watch or RequestBuild
+type WatchEvent struct {
+	ID     uint64       // same as History()
+	Reason string       // eg: "change web/main.go, change go.mod"
+	Result *BuildResult // nil when Err is set
+	Err    error
+}
+
+// watcher receives the events of scheduled builds until its context is done
+type watcher struct {
+	ctx     context.Context
+	events  chan WatchEvent
+	sending sync.WaitGroup // sends in flight, the channel is closed after them
+	mu      sync.Mutex     // serializes send, dropping the oldest event needs a stable count
+}
+
+// Watch polls the files returned by InputFiles every Config.WatchInterval and
+// calls RequestBuild for each change, so bursts are debounced into one build
+// New files are picked up when they join the package graph, eg: web/dom_js.go
+// Outputs and temp files are never watched, eg: main.wasm embedded by a server
+// The returned channel reports every scheduled build and is closed once ctx is done
+// Builds never wait for it, up to maxPendingWatchEvents are kept and the oldest
+// ones are dropped when nobody drains the channel
+func (h *GoBuild) Watch(ctx context.Context) (<-chan WatchEvent, error) {
+	pkgs, err := h.listPackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Taken before returning, changes made right after Watch are not missed
+	stamps := h.watchStamps(pkgs)
+
+	w := &watcher{ctx: ctx, events: make(chan WatchEvent, maxPendingWatchEvents)}
+	s := h.scheduler
+	s.mu.Lock()
+	if s.watchers == nil {
+		s.watchers = map[*watcher]bool{}
+	}
+	s.watchers[w] = true
+	s.mu.Unlock()
+
+	go func() {
+		h.pollInputs(ctx, stamps)
+
+		s.mu.Lock()
+		delete(s.watchers, w)
+		s.mu.Unlock()
+
+		w.sending.Wait()
+		close(w.events)
+	}()
+
+	return w.events, nil
+}
+
+// pollInputs requests a build whenever an input changes, until ctx is done
+func (h *GoBuild) pollInputs(ctx context.Context, stamps map[string]string) {
+	interval := h.config.WatchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := restamp(stamps)
+		if equalStamps(stamps, current) {
+			continue
+		}
+
+		// Something moved, list again to follow the package graph, eg: a new import
+		if listed, err := h.listPackages(ctx); err == nil {
+			current = h.watchStamps(listed)
+		} else if ctx.Err() == nil && h.config.Logger != nil {
+			h.config.Logger("Watch: listing inputs failed:", err)
+		}
+
+		for _, file := range changedFiles(stamps, current) {
+			h.RequestBuild("change " + h.relativeToRoot(file))
+		}
+		stamps = current
+	}
+}
+
+// watchStamps returns a stamp for every input file, local package directory and embed glob
+// Directory stamps only cover source file names, embed globs their matches, eg: web/static/*
+// both reveal files joining the graph
+func (h *GoBuild) watchStamps(pkgs []listedPackage) map[string]string {
+	ignored := h.ignoredFiles()
+	stamps := map[string]string{}
+	for _, file := range inputFiles(pkgs) {
+		if !ignored[resolvePath(file)] {
+			stamps[file] = fileStamp(file)
+		}
+	}
+	for _, p := range pkgs {
+		if !p.local() {
+			continue
+		}
+		stamps[p.Dir+string(filepath.Separator)] = hashDirNames(p.Dir)
+		for _, glob := range p.embedGlobs() {
+			stamps[glob] = hashGlob(glob)
+		}
+	}
+	return stamps
+}
+
+// ignoredFiles returns the absolute paths of the outputs listed by UnobservedFiles
+// Temp files are unique per build, they are never part of the package graph
+func (h *GoBuild) ignoredFiles() map[string]bool {
+	ignored := map[string]bool{}
+	for _, name := range h.UnobservedFiles() {
+		if abs, err := filepath.Abs(filepath.Join(h.config.OutFolderRelativePath, name)); err == nil {
+			ignored[resolvePath(abs)] = true
+		}
+	}
+	return ignored
+}
+
+// restamp computes the stamps of the same paths again
+func restamp(stamps map[string]string) map[string]string {
+	current := make(map[string]string, len(stamps))
+	for path := range stamps {
+		if strings.HasSuffix(path, string(filepath.Separator)) {
+			current[path] = hashDirNames(path)
+		} else if isGlob(path) {
+			current[path] = hashGlob(path)
+		} else {
+			current[path] = fileStamp(path)
+		}
+	}
+	return current
+}
+
+// changedFiles returns the sorted files added, removed or modified between two stamp sets
+// Directories and embed globs are left out, the files they reveal are listed on their own
+func changedFiles(before, after map[string]string) []string {
+	var files []string
+	for path, stamp := range after {
+		if old, ok := before[path]; !ok || old != stamp {
+			files = append(files, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			files = append(files, path)
+		}
+	}
+
+	kept := files[:0]
+	for _, file := range files {
+		if !strings.HasSuffix(file, string(filepath.Separator)) && !isGlob(file) {
+			kept = append(kept, file)
+		}
+	}
+	sort.Strings(kept)
+	return kept
+}
+
+func equalStamps(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, stamp := range a {
+		if b[path] != stamp {
+			return false
+		}
+	}
+	return true
+}
+
+// fileStamp identifies the file version by size and modification time, "" when missing
+func fileStamp(name string) string {
+	info, err := os.Stat(name)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d %d", info.Size(), info.ModTime().UnixNano())
+}
+
+// subscribed returns the watchers still listening, s.mu must be held
+// Each one counts a send in flight so Watch closes its channel after it
+func (s *scheduler) subscribed() []*watcher {
+	var watchers []*watcher
+	for w := range s.watchers {
+		w.sending.Add(1)
+		watchers = append(watchers, w)
+	}
+	return watchers
+}
+
+// send queues ev unless the watcher stopped listening, it never blocks
+// A full channel loses its oldest event, the latest build matters most
+func (w *watcher) send(ev WatchEvent) {
+	defer w.sending.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for w.ctx.Err() == nil {
+		select {
+		case w.events <- ev:
+			return
+		default:
+		}
+		select {
+		case <-w.events:
+		default:
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package gobuild
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// nextEvent waits for the next watch event or fails after timeout
+func nextEvent(t *testing.T, events <-chan WatchEvent, timeout time.Duration) WatchEvent {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(timeout):
+		t.Fatal("Timed out waiting for a watch event")
+		return WatchEvent{}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"go.mod":          "module example.com/app\n\ngo 1.22\n",
+		"web/main.go":     "package main\n\nimport _ \"example.com/app/ui\"\n\nfunc main() {}\n",
+		"ui/ui.go":        "package ui\n\nimport \"embed\"\n\n//go:embed static *.txt\nvar Assets embed.FS\n",
+		"ui/static/a.css": "a",
+		"ui/notes.txt":    "notes",
+		"ui/README.md":    "docs",
+		"ui/other.go":     "//go:build ignore\n\npackage ui\n",
+		"server/app.go":   "package server\n",
+	})
+	tool := writeFakeToolchain(t, tempDir, fakeBuildScript)
+
+	gb := New(&Config{
+		AppRootDir:                tempDir,
+		Command:                   tool,
+		MainInputFileRelativePath: "web/main.go",
+		OutName:                   "main",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     filepath.Join(tempDir, "web"),
+		Debounce:                  50 * time.Millisecond,
+		WatchInterval:             20 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := gb.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	// A burst of saves produces one build
+	writeFiles(t, tempDir, map[string]string{"web/main.go": "package main\n\nimport _ \"example.com/app/ui\"\n\nfunc main() { println() }\n"})
+	writeFiles(t, tempDir, map[string]string{"ui/ui.go": "package ui\n\nimport \"embed\"\n\n//go:embed static *.txt\nvar Assets embed.FS\n\nvar X = 1\n"})
+
+	ev := nextEvent(t, events, 5*time.Second)
+	if ev.Err != nil || ev.Result == nil {
+		t.Fatalf("Expected a successful build, got %v", ev.Err)
+	}
+	if !strings.Contains(ev.Reason, filepath.Join("web", "main.go")) || !strings.Contains(ev.Reason, filepath.Join("ui", "ui.go")) {
+		t.Errorf("Expected both changes in one build, got reason %q", ev.Reason)
+	}
+
+	// Outputs, unrelated files and excluded sources do not trigger builds
+	writeFiles(t, tempDir, map[string]string{
+		"web/main.wasm":      "new output",
+		"web/main_temp.wasm": "temp",
+		"ui/README.md":       "more docs",
+		"ui/other.go":        "//go:build ignore\n\npackage ui\n\nvar Y = 2\n",
+		"server/app.go":      "package server\n\nvar Z = 3\n",
+	})
+	select {
+	case ev := <-events:
+		t.Fatalf("Unexpected build for %q", ev.Reason)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// A new file joining the package graph triggers a build
+	writeFiles(t, tempDir, map[string]string{"ui/extra.go": "package ui\n"})
+	ev = nextEvent(t, events, 5*time.Second)
+	if !strings.Contains(ev.Reason, filepath.Join("ui", "extra.go")) {
+		t.Errorf("Expected a build for the new file, got reason %q", ev.Reason)
+	}
+
+	// New assets matched by //go:embed trigger a build, in nested directories too
+	for _, asset := range []string{"ui/static/b.css", "ui/static/img/logo.svg", "ui/more.txt"} {
+		writeFiles(t, tempDir, map[string]string{asset: "new"})
+		ev = nextEvent(t, events, 5*time.Second)
+		if !strings.Contains(ev.Reason, filepath.FromSlash(asset)) {
+			t.Errorf("Expected a build for the new asset %s, got reason %q", asset, ev.Reason)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Expected no more events after cancel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Events channel was not closed after cancel")
+	}
+
+	if _, err := os.Stat(gb.FinalOutputPath()); err != nil {
+		t.Errorf("Expected the watched build output: %v", err)
+	}
+}
+
+func TestWatchEventsDropOldestWhenNotDrained(t *testing.T) {
+	w := &watcher{ctx: context.Background(), events: make(chan WatchEvent, maxPendingWatchEvents)}
+
+	const sent = maxPendingWatchEvents + 5
+	for id := uint64(1); id <= sent; id++ {
+		w.sending.Add(1)
+		w.send(WatchEvent{ID: id})
+	}
+	w.sending.Wait()
+
+	if len(w.events) != maxPendingWatchEvents {
+		t.Fatalf("Expected %d pending events, got %d", maxPendingWatchEvents, len(w.events))
+	}
+	if first := <-w.events; first.ID != sent-maxPendingWatchEvents+1 {
+		t.Errorf("Expected the oldest events to be dropped, first pending is %d", first.ID)
+	}
+}